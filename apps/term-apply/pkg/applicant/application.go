@@ -1,33 +1,144 @@
 package applicant
 
 import (
-	"strconv"
+	"errors"
+	"fmt"
 	"time"
 )
 
-type application struct {
-	appliedDate string
-	github      string
-	name        string
-	email       string
-	roleApplied string
-	offerGiven  bool
-	rejected    bool
+// Application is a single application by a candidate, covering the full
+// applicant schema documented in the README. The tags map each field to its
+// DynamoDB attribute and to its JSON form in the bolt store and write journal.
+//
+// Fields other than the candidate's name, email, role and answers are set by staff
+// and are never changed by candidate edits.
+type Application struct {
+	AppliedDate         int64                `json:"applied_date,string" dynamodbav:"applied_date"` // unix time
+	Email               string               `json:"email" dynamodbav:"email"`
+	Name                string               `json:"name" dynamodbav:"name"`
+	Github              string               `json:"github" dynamodbav:"github"`
+	RoleApplied         string               `json:"role_applied" dynamodbav:"role_applied"` // title of the first role when the candidate applied
+	RoleID              string               `json:"role_id,omitempty" dynamodbav:"role_id,omitempty"`
+	Roles               []AppliedRole        `json:"roles,omitempty" dynamodbav:"roles,omitempty"`                 // every role applied for, see AppliedRoles
+	Answers             map[string]string    `json:"answers,omitempty" dynamodbav:"answers,omitempty"`             // screening question ID to the candidate's answer
+	RoleOverride        string               `json:"role_override,omitempty" dynamodbav:"role_override,omitempty"` // only set on single role applications, see AppliedRole.Override
+	ResumeReview        bool                 `json:"resume_review,omitempty" dynamodbav:"resume_review,omitempty"`
+	ResumeReviewDate    int64                `json:"resume_review_date,omitempty" dynamodbav:"resume_review_date,omitempty"`
+	Interviews          map[string]Interview `json:"interviews,omitempty" dynamodbav:"interviews,omitempty"`
+	IgnoreWorkflow      bool                 `json:"ignore_workflow,omitempty" dynamodbav:"ignore_workflow,omitempty"`
+	Flags               map[string]int64     `json:"flags,omitempty" dynamodbav:"flags,omitempty"` // workflow rule name to when it flagged the application
+	OfferGiven          bool                 `json:"offer_given" dynamodbav:"offer_given"`
+	OfferDate           int64                `json:"offer_date,omitempty" dynamodbav:"offer_date,omitempty"`
+	OfferAccepted       bool                 `json:"offer_accepted,omitempty" dynamodbav:"offer_accepted,omitempty"`
+	OfferAcceptedDate   int64                `json:"offer_accepted_date,omitempty" dynamodbav:"offer_accepted_date,omitempty"`
+	Rejected            bool                 `json:"rejected" dynamodbav:"rejected"`
+	RejectedDate        int64                `json:"rejected_date,omitempty" dynamodbav:"rejected_date,omitempty"`
+	RejectedMsgOverride []byte               `json:"rejected_msg_override,omitempty" dynamodbav:"rejected_msg_override,omitempty"`
+	State               State                `json:"state,omitempty" dynamodbav:"state,omitempty"`
+	StateHistory        []StateChange        `json:"state_history,omitempty" dynamodbav:"state_history,omitempty"`
+	Version             int64                `json:"version" dynamodbav:"version"` // incremented on every write, 0 before the first
 }
 
-func NewApplication(github, name, email, roleApplied string) (application, error) {
-	if err := checkForInputErrors(name, email, roleApplied); err != nil {
-		return application{}, err
+// Interview is one round of interviews and its scorecard, keyed by its round
+// number in Application.Interviews
+type Interview struct {
+	Date         int64          `json:"date" dynamodbav:"date"` // unix time
+	Pass         bool           `json:"pass" dynamodbav:"pass"`
+	Notes        []byte         `json:"notes,omitempty" dynamodbav:"notes,omitempty"`
+	Interviewer  string         `json:"interviewer,omitempty" dynamodbav:"interviewer,omitempty"`
+	Rating       int            `json:"rating,omitempty" dynamodbav:"rating,omitempty"`             // overall, 1 to 5
+	Competencies map[string]int `json:"competencies,omitempty" dynamodbav:"competencies,omitempty"` // competency name to rating, 1 to 5
+}
+
+// NewApplication returns a new application for roles, in the order the
+// candidate chose them
+func NewApplication(github, name, email string, roles ...Role) (Application, error) {
+	var first Role
+	if len(roles) > 0 {
+		first = roles[0]
+	}
+	if err := checkForInputErrors(name, email, first.Title); err != nil {
+		return Application{}, err
 	}
 
-	appliedDate := strconv.FormatInt(time.Now().Unix(), 10)
-	return application{
-		appliedDate: appliedDate,
-		github:      github,
-		name:        name,
-		email:       email,
-		roleApplied: roleApplied,
-		offerGiven:  false,
-		rejected:    false,
+	now := time.Now().Unix()
+	return Application{
+		AppliedDate:  now,
+		Github:       github,
+		Name:         name,
+		Email:        email,
+		RoleApplied:  first.Title,
+		RoleID:       first.ID,
+		Roles:        newAppliedRoles(roles),
+		OfferGiven:   false,
+		Rejected:     false,
+		State:        StateApplied,
+		StateHistory: []StateChange{{State: StateApplied, Date: now}},
 	}, nil
 }
+
+// sameCandidateFields reports whether app and other agree on everything the
+// candidate can edit
+func (app Application) sameCandidateFields(other Application) bool {
+	return app.Name == other.Name &&
+		app.Email == other.Email &&
+		app.RoleApplied == other.RoleApplied &&
+		app.RoleID == other.RoleID &&
+		sameRoleIDs(app.AppliedRoles(), other.AppliedRoles()) &&
+		sameAnswers(app.Answers, other.Answers)
+}
+
+// setCandidateFields copies everything the candidate can edit from other,
+// leaving the fields set by staff alone
+func (app *Application) setCandidateFields(other Application) {
+	app.Name = other.Name
+	app.Email = other.Email
+	app.RoleApplied = other.RoleApplied
+	app.RoleID = other.RoleID
+	app.Roles = other.Roles
+	app.Answers = other.Answers
+}
+
+// VersionConflictError is returned when an application was modified after
+// it was read, so the write was rejected instead of overwriting the change.
+type VersionConflictError struct {
+	Email       string
+	AppliedDate int64
+	Version     int64 // the version the write expected
+}
+
+func (err *VersionConflictError) Error() string {
+	return fmt.Sprintf(
+		"application for %s (applied at %d) was modified concurrently, expected version %d",
+		err.Email,
+		err.AppliedDate,
+		err.Version,
+	)
+}
+
+// ApplicationNotFoundError is returned when a write expects an application
+// that is not stored, for example because it was deleted after it was read
+type ApplicationNotFoundError struct {
+	Email       string
+	AppliedDate int64
+}
+
+func (err *ApplicationNotFoundError) Error() string {
+	return fmt.Sprintf("expected record not found: %s (applied at %d)", err.Email, err.AppliedDate)
+}
+
+// isStaleWrite reports whether err means a write was based on an application
+// that has since changed or gone, so retrying it cannot succeed
+func isStaleWrite(err error) bool {
+	var conflict *VersionConflictError
+	var notFound *ApplicationNotFoundError
+	return errors.As(err, &conflict) || errors.As(err, &notFound)
+}
+
+func newVersionConflict(app Application) *VersionConflictError {
+	return &VersionConflictError{
+		Email:       app.Email,
+		AppliedDate: app.AppliedDate,
+		Version:     app.Version,
+	}
+}
@@ -0,0 +1,148 @@
+package applicant
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+)
+
+// fullApplication sets every field of Application
+func fullApplication() Application {
+	return Application{
+		AppliedDate: 100,
+		Email:       "candy@date.com",
+		Name:        "Candy Date",
+		Github:      "candy",
+		RoleApplied: "Software Engineer",
+		RoleID:      "software-engineer",
+		Roles: []AppliedRole{
+			{ID: "software-engineer", Title: "Software Engineer", Override: "Senior Software Engineer"},
+			{ID: "site-reliability-engineer", Title: "Site Reliability Engineer", Rejected: true, RejectedDate: 150},
+		},
+		Answers:          map[string]string{"work-authorization": "Yes"},
+		RoleOverride:     "Senior Software Engineer",
+		ResumeReview:     true,
+		ResumeReviewDate: 200,
+		Interviews: map[string]Interview{
+			"1": {
+				Date:         300,
+				Pass:         true,
+				Notes:        []byte("strong systems design"),
+				Interviewer:  "staff",
+				Rating:       4,
+				Competencies: map[string]int{"systems design": 5, "communication": 4},
+			},
+			"2": {Date: 400, Pass: false},
+		},
+		IgnoreWorkflow:      true,
+		Flags:               map[string]int64{"idle-resume-review": 250},
+		OfferGiven:          true,
+		OfferDate:           500,
+		OfferAccepted:       true,
+		OfferAcceptedDate:   600,
+		Rejected:            true,
+		RejectedDate:        700,
+		RejectedMsgOverride: []byte("Thanks for your time"),
+		State:               StateRejected,
+		StateHistory: []StateChange{
+			{State: StateApplied, Date: 100},
+			{State: StateRejected, Date: 700},
+		},
+		Version: 3,
+	}
+}
+
+func TestApplicationDynamoDBRoundTrip(t *testing.T) {
+	app := fullApplication()
+	item, err := dynamodbattribute.MarshalMap(app)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	numbers := []string{"applied_date", "resume_review_date", "offer_date", "offer_accepted_date", "rejected_date", "version"}
+	for _, attr := range numbers {
+		if item[attr] == nil || item[attr].N == nil {
+			t.Errorf("expected %s to be a number, got %v", attr, item[attr])
+		}
+	}
+	strings := []string{"email", "name", "github", "role_applied", "role_override", "state"}
+	for _, attr := range strings {
+		if item[attr] == nil || item[attr].S == nil {
+			t.Errorf("expected %s to be a string, got %v", attr, item[attr])
+		}
+	}
+	bools := []string{"resume_review", "ignore_workflow", "offer_given", "offer_accepted", "rejected"}
+	for _, attr := range bools {
+		if item[attr] == nil || item[attr].BOOL == nil {
+			t.Errorf("expected %s to be a bool, got %v", attr, item[attr])
+		}
+	}
+	if item["rejected_msg_override"] == nil || item["rejected_msg_override"].B == nil {
+		t.Errorf("expected rejected_msg_override to be binary, got %v", item["rejected_msg_override"])
+	}
+	if change := item["state_history"].L[1].M; change["state"].S == nil || change["date"].N == nil {
+		t.Errorf("unexpected state history attributes: %v", change)
+	}
+	interview := item["interviews"].M["1"].M
+	if interview["date"].N == nil || interview["pass"].BOOL == nil || interview["notes"].B == nil ||
+		interview["interviewer"].S == nil || interview["rating"].N == nil || interview["competencies"].M["communication"].N == nil {
+		t.Errorf("unexpected interview attributes: %v", interview)
+	}
+
+	got, err := applicationFromItem(item)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, app) {
+		t.Fatalf("application did not round trip:\n got %+v\nwant %+v", got, app)
+	}
+}
+
+func TestApplicationDynamoDBOmitsUnsetFields(t *testing.T) {
+	app, err := NewApplication("candy", "Candy Date", "candy@date.com", DefaultRoles[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	item, err := dynamodbattribute.MarshalMap(app)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, attr := range []string{"role_override", "resume_review", "interviews", "offer_date", "rejected_msg_override"} {
+		if _, ok := item[attr]; ok {
+			t.Errorf("expected unset %s to be omitted", attr)
+		}
+	}
+
+	got, err := applicationFromItem(item)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, app) {
+		t.Fatalf("application did not round trip:\n got %+v\nwant %+v", got, app)
+	}
+}
+
+func TestApplicationJSONRoundTrip(t *testing.T) {
+	app := fullApplication()
+	data, err := json.Marshal(app)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// applied_date stays a string so existing journals and bolt files load
+	var raw map[string]interface{}
+	json.Unmarshal(data, &raw)
+	if raw["applied_date"] != "100" {
+		t.Fatalf("expected applied_date to be encoded as a string, got %v", raw["applied_date"])
+	}
+
+	var got Application
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, app) {
+		t.Fatalf("application did not round trip:\n got %+v\nwant %+v", got, app)
+	}
+}
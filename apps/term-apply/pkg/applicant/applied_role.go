@@ -0,0 +1,171 @@
+package applicant
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+/*
+
+A candidate can apply for several roles in one application. Each role keeps
+its own outcome, so staff can reject the candidate for one role while the
+application proceeds for another, and can consider them for a different role
+through the role's override. Rejecting the last role still being considered
+rejects the whole application.
+
+Applications saved before multiple roles were supported only have
+role_applied, role_id and role_override, which are read as a single role.
+role_applied and role_id are still set to the first role.
+
+*/
+
+// AppliedRole is one of the roles an application is for
+type AppliedRole struct {
+	ID           string `json:"id" dynamodbav:"id"`
+	Title        string `json:"title" dynamodbav:"title"`                                     // role title when the candidate applied
+	Override     string `json:"role_override,omitempty" dynamodbav:"role_override,omitempty"` // role staff are considering the candidate for instead
+	Rejected     bool   `json:"rejected,omitempty" dynamodbav:"rejected,omitempty"`
+	RejectedDate int64  `json:"rejected_date,omitempty" dynamodbav:"rejected_date,omitempty"`
+	KnockedOut   bool   `json:"knocked_out,omitempty" dynamodbav:"knocked_out,omitempty"` // rejected by a knockout answer
+}
+
+// RoleNotAppliedError is returned when staff edit a role the application is
+// not for
+type RoleNotAppliedError struct {
+	RoleID string
+}
+
+func (err *RoleNotAppliedError) Error() string {
+	return fmt.Sprintf("the application is not for role %s", err.RoleID)
+}
+
+// AppliedRoles returns the roles app is for, in the order the candidate
+// chose them
+func (app Application) AppliedRoles() []AppliedRole {
+	if len(app.Roles) > 0 {
+		roles := make([]AppliedRole, len(app.Roles))
+		copy(roles, app.Roles)
+		return roles
+	}
+	if app.RoleID == "" && app.RoleApplied == "" {
+		return nil
+	}
+	return []AppliedRole{{ID: app.RoleID, Title: app.RoleApplied, Override: app.RoleOverride}}
+}
+
+// RoleTitles lists the titles of the roles app is for
+func (app Application) RoleTitles() string {
+	roles := app.AppliedRoles()
+	titles := make([]string, len(roles))
+	for i, role := range roles {
+		titles[i] = role.Title
+	}
+	return strings.Join(titles, ", ")
+}
+
+func newAppliedRoles(roles []Role) []AppliedRole {
+	applied := make([]AppliedRole, len(roles))
+	for i, role := range roles {
+		applied[i] = AppliedRole{ID: role.ID, Title: role.Title}
+	}
+	return applied
+}
+
+// mergeAppliedRoles returns the roles a candidate chose, keeping what staff
+// recorded for the roles they had already applied for. Roles the candidate
+// was rejected for are kept even if they are no longer chosen, so dropping
+// and choosing a role again does not undo a rejection.
+func mergeAppliedRoles(current, chosen []AppliedRole) []AppliedRole {
+	kept := make(map[string]AppliedRole, len(current))
+	for _, role := range current {
+		kept[role.ID] = role
+	}
+	merged := make([]AppliedRole, 0, len(chosen))
+	for _, role := range chosen {
+		if previous, ok := kept[role.ID]; ok {
+			role.Override = previous.Override
+			role.Rejected = previous.Rejected
+			role.RejectedDate = previous.RejectedDate
+			role.KnockedOut = previous.KnockedOut
+			delete(kept, role.ID)
+		}
+		merged = append(merged, role)
+	}
+	for _, role := range current {
+		if _, ok := kept[role.ID]; ok && role.Rejected {
+			merged = append(merged, role)
+		}
+	}
+	return merged
+}
+
+// sameRoleIDs reports whether a and b are for the same roles in the same
+// order
+func sameRoleIDs(a, b []AppliedRole) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].ID != b[i].ID {
+			return false
+		}
+	}
+	return true
+}
+
+// editRole applies edit to the role of app with id
+func (app *Application) editRole(id string, edit func(*AppliedRole)) error {
+	roles := app.AppliedRoles()
+	for i := range roles {
+		if roles[i].ID == id {
+			edit(&roles[i])
+			app.Roles = roles
+			return nil
+		}
+	}
+	return &RoleNotAppliedError{RoleID: id}
+}
+
+// rejectRole rejects app for the role with id, and rejects app itself once
+// no role is left
+func (app *Application) rejectRole(id string, at time.Time) error {
+	err := app.editRole(id, func(role *AppliedRole) {
+		if !role.Rejected {
+			role.Rejected = true
+			role.RejectedDate = at.Unix()
+		}
+	})
+	if err != nil {
+		return err
+	}
+	for _, role := range app.Roles {
+		if !role.Rejected {
+			return nil
+		}
+	}
+	if app.CanTransition(StateRejected) {
+		return app.transition(StateRejected, at)
+	}
+	return nil
+}
+
+// RejectRole rejects app for the role with roleID while the application
+// proceeds for its other roles
+func (a *ApplicantManager) RejectRole(ctx context.Context, actor string, app Application, roleID string) (Application, error) {
+	return a.staffEdit(ctx, actor, app, func(app *Application) error {
+		return app.rejectRole(roleID, time.Now())
+	})
+}
+
+// OverrideRole records that staff are considering the candidate for the role
+// titled override instead of the role with roleID. An empty override clears
+// it.
+func (a *ApplicantManager) OverrideRole(ctx context.Context, actor string, app Application, roleID, override string) (Application, error) {
+	return a.staffEdit(ctx, actor, app, func(app *Application) error {
+		return app.editRole(roleID, func(role *AppliedRole) {
+			role.Override = override
+		})
+	})
+}
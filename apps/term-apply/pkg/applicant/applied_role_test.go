@@ -0,0 +1,153 @@
+package applicant
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestLegacyAppliedRoles(t *testing.T) {
+	app := Application{RoleID: "software-engineer", RoleApplied: "Software Engineer", RoleOverride: "Senior Software Engineer"}
+	want := []AppliedRole{{ID: "software-engineer", Title: "Software Engineer", Override: "Senior Software Engineer"}}
+	if roles := app.AppliedRoles(); !reflect.DeepEqual(roles, want) {
+		t.Fatalf("unexpected roles %+v", roles)
+	}
+	if roles := (Application{}).AppliedRoles(); len(roles) != 0 {
+		t.Fatalf("expected no roles, got %+v", roles)
+	}
+}
+
+func TestRolesProceedSeparately(t *testing.T) {
+	store := NewMemoryStore()
+	am := newTestManager(t, store)
+	roleIDs := []string{"software-engineer", "senior-software-engineer"}
+
+	if _, err := am.AddApplicant(context.Background(), "candy", "Candy Date", "candy@date.com", []string{"software-engineer", "software-engineer"}, nil); err == nil {
+		t.Fatalf("expected a repeated role to be refused")
+	}
+	receipt, err := am.AddApplicant(context.Background(), "candy", "Candy Date", "candy@date.com", roleIDs, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if receipt.RoleApplied != "Software Engineer, Senior Software Engineer" {
+		t.Fatalf("unexpected receipt %+v", receipt)
+	}
+
+	app, _ := store.GetApplication(context.Background(), "candy")
+	if app.RoleID != "software-engineer" || len(app.Roles) != 2 {
+		t.Fatalf("expected both roles to be stored: %+v", app)
+	}
+	app, err = am.RejectRole(context.Background(), "staff", app, "software-engineer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	app, err = am.OverrideRole(context.Background(), "staff", app, "senior-software-engineer", "Staff Engineer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := am.RejectRole(context.Background(), "staff", app, "intern"); !errors.As(err, new(*RoleNotAppliedError)) {
+		t.Fatalf("expected a role the application is not for to be refused, got %v", err)
+	}
+
+	status, _ := am.ApplicationStatus(context.Background(), "candy")
+	want := []RoleStatus{{Title: "Software Engineer", Outcome: "Not selected"}, {Title: "Senior Software Engineer", Outcome: "In progress"}}
+	if status.Closed || !reflect.DeepEqual(status.Roles, want) {
+		t.Fatalf("expected the application to proceed for one role: %+v", status)
+	}
+
+	// Resubmitting keeps what staff recorded for each role, and dropping a
+	// role does not undo its rejection
+	addAndWait(t, am, "candy", "Candy Dated", "candy@date.com", "senior-software-engineer")
+	app, _ = store.GetApplication(context.Background(), "candy")
+	if app.RoleID != "senior-software-engineer" || len(app.Roles) != 2 || app.Roles[0].Override != "Staff Engineer" || !app.Roles[1].Rejected {
+		t.Fatalf("expected staff changes to survive the edit: %+v", app.Roles)
+	}
+	if _, err := am.AddApplicant(context.Background(), "candy", "Candy Dated", "candy@date.com", roleIDs, nil); err != nil {
+		t.Fatal(err)
+	}
+	app, _ = store.GetApplication(context.Background(), "candy")
+	if !app.Roles[0].Rejected || app.Roles[1].Override != "Staff Engineer" {
+		t.Fatalf("expected the role chosen again to stay rejected: %+v", app.Roles)
+	}
+
+	// Rejecting the last role still considered rejects the application
+	app, err = am.RejectRole(context.Background(), "staff", app, "senior-software-engineer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if app.CurrentState() != StateRejected || !app.Rejected {
+		t.Fatalf("expected the application to be rejected: %+v", app)
+	}
+}
+
+func TestRejectRoleKeepsOtherRolesOpen(t *testing.T) {
+	store := NewMemoryStore()
+	am := newTestManager(t, store)
+	if _, err := am.AddApplicant(context.Background(), "candy", "Candy Date", "candy@date.com", []string{"software-engineer", "senior-software-engineer"}, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	app, _ := store.GetApplication(context.Background(), "candy")
+	if _, err := am.RejectRole(context.Background(), "staff", app, "senior-software-engineer"); err != nil {
+		t.Fatal(err)
+	}
+	stored, _ := store.GetApplication(context.Background(), "candy")
+	if !stored.IsOpen() || stored.Rejected || stored.CurrentState() != StateApplied {
+		t.Fatalf("expected the application to stay open: %+v", stored)
+	}
+	if stored.Roles[0].Rejected || !stored.Roles[1].Rejected || stored.Roles[1].RejectedDate == 0 {
+		t.Fatalf("expected only the senior role to be rejected: %+v", stored.Roles)
+	}
+}
+
+func TestRejectingLastRoleRejectsApplication(t *testing.T) {
+	store := NewMemoryStore()
+	am := newTestManager(t, store)
+	if _, err := am.AddApplicant(context.Background(), "candy", "Candy Date", "candy@date.com", []string{"software-engineer", "senior-software-engineer"}, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	app, _ := store.GetApplication(context.Background(), "candy")
+	app, err := am.RejectRole(context.Background(), "staff", app, "software-engineer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := am.RejectRole(context.Background(), "staff", app, "senior-software-engineer"); err != nil {
+		t.Fatal(err)
+	}
+	stored, _ := store.GetApplication(context.Background(), "candy")
+	if stored.IsOpen() || !stored.Rejected || stored.CurrentState() != StateRejected {
+		t.Fatalf("expected the application to be rejected: %+v", stored)
+	}
+	status, _ := am.ApplicationStatus(context.Background(), "candy")
+	if !status.Closed {
+		t.Fatalf("expected the candidate to see the application closed: %+v", status)
+	}
+}
+
+func TestKnockoutRejectsOnlyItsRole(t *testing.T) {
+	store := NewMemoryStore()
+	catalog, _ := NewRoleCatalog([]Role{
+		{ID: "swe", Title: "Software Engineer", Open: true, Questions: []Question{
+			{ID: "work-authorization", Text: "Authorized to work in the US?"},
+		}},
+		{ID: "sre", Title: "Site Reliability Engineer", Open: true, Questions: []Question{
+			{ID: "work-authorization", Text: "Authorized to work in the US?"},
+			{ID: "on-call", Text: "Can you take part in an on-call rotation?", Knockout: map[string]KnockoutAction{"No": KnockoutReject}},
+		}},
+	})
+	am, err := NewApplicantManager(store, &stubBlobStore{}, ManagerConfig{Roles: catalog})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	answers := map[string]string{"work-authorization": "Yes", "on-call": "No"}
+	if _, err := am.AddApplicant(context.Background(), "candy", "Candy Date", "candy@date.com", []string{"swe", "sre"}, answers); err != nil {
+		t.Fatal(err)
+	}
+	app, _ := store.GetApplication(context.Background(), "candy")
+	if !app.IsOpen() || app.Roles[0].Rejected || !app.Roles[1].Rejected {
+		t.Fatalf("expected only the role asking the question to be rejected: %+v", app)
+	}
+}
@@ -0,0 +1,409 @@
+package applicant
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+/*
+
+BoltStore keeps applications in an embedded bbolt database for environments
+without a DynamoDB table (career fairs, local testing).
+
+Layout:
+
+> applications: email + "\x00" + applied_date -> JSON encoded application <br>
+> github: one nested bucket per github user, keyed by zero padded
+> applied_date + "\x00" + email so the last key is the most recent
+> application <br>
+> role_counts: role ID -> number of applications counted for it <br>
+
+All writes happen inside a single bolt transaction, so an email change
+recreate is atomic just like the DynamoDB transaction.
+
+*/
+
+var (
+	applicationsBucket = []byte("applications")
+	githubBucket       = []byte("github")
+	roleCountsBucket   = []byte("role_counts")
+)
+
+type BoltStore struct {
+	db *bolt.DB
+}
+
+func NewBoltStore(path string) (*BoltStore, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("cannot open bolt database %s: %w", path, err)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{applicationsBucket, githubBucket, roleCountsBucket} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &BoltStore{db: db}, nil
+}
+
+func (b *BoltStore) Close() error {
+	return b.db.Close()
+}
+
+// Returns the provided user's most recent application
+func (b *BoltStore) GetApplication(ctx context.Context, user string) (Application, error) {
+	if err := ctx.Err(); err != nil {
+		return Application{}, err
+	}
+
+	var app Application
+	err := b.db.View(func(tx *bolt.Tx) error {
+		index := tx.Bucket(githubBucket).Bucket([]byte(user))
+		if index == nil {
+			return newEmptyResult(user)
+		}
+		_, primary := index.Cursor().Last()
+		if primary == nil {
+			return newEmptyResult(user)
+		}
+		record, err := getRecord(tx, primary)
+		if err != nil {
+			return err
+		}
+		if record == nil {
+			return fmt.Errorf("index for %s references missing application", user)
+		}
+		app = *record
+		return nil
+	})
+	return app, err
+}
+
+func (b *BoltStore) PutApplication(ctx context.Context, app Application) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return b.db.Update(func(tx *bolt.Tx) error {
+		existing, err := getRecord(tx, primaryKey(app.Email, app.AppliedDate))
+		if err != nil {
+			return err
+		}
+		// Never overwrite an existing application
+		if existing != nil {
+			return newVersionConflict(app)
+		}
+		app.Version = 1
+		return putRecord(tx, app)
+	})
+}
+
+func (b *BoltStore) UpdateApplication(ctx context.Context, app Application) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return b.db.Update(func(tx *bolt.Tx) error {
+		record, err := getRecord(tx, primaryKey(app.Email, app.AppliedDate))
+		if err != nil {
+			return err
+		}
+		if record == nil {
+			return &ApplicationNotFoundError{Email: app.Email, AppliedDate: app.AppliedDate}
+		}
+		if record.Version != app.Version {
+			return newVersionConflict(app)
+		}
+		record.setCandidateFields(app)
+		record.Version++
+		return putRecord(tx, *record)
+	})
+}
+
+func (b *BoltStore) RecreateApplication(ctx context.Context, app Application, prevEmail string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return b.db.Update(func(tx *bolt.Tx) error {
+		record, err := getRecord(tx, primaryKey(prevEmail, app.AppliedDate))
+		if err != nil {
+			return err
+		}
+		if record == nil {
+			return &ApplicationNotFoundError{Email: prevEmail, AppliedDate: app.AppliedDate}
+		}
+
+		if record.Version != app.Version {
+			return newVersionConflict(app)
+		}
+		existing, err := getRecord(tx, primaryKey(app.Email, app.AppliedDate))
+		if err != nil {
+			return err
+		}
+		if existing != nil {
+			return newVersionConflict(app)
+		}
+
+		if err := deleteRecord(tx, *record); err != nil {
+			return err
+		}
+
+		// Preserve fields the candidate cannot change
+		record.setCandidateFields(app)
+		record.Version++
+		return putRecord(tx, *record)
+	})
+}
+
+func (b *BoltStore) SaveApplication(ctx context.Context, app Application) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return b.db.Update(func(tx *bolt.Tx) error {
+		record, err := getRecord(tx, primaryKey(app.Email, app.AppliedDate))
+		if err != nil {
+			return err
+		}
+		if record == nil {
+			return &ApplicationNotFoundError{Email: app.Email, AppliedDate: app.AppliedDate}
+		}
+		if record.Version != app.Version {
+			return newVersionConflict(app)
+		}
+		app.Version++
+		return putRecord(tx, app)
+	})
+}
+
+// ListApplications pages through applications in primary key order
+func (b *BoltStore) ListApplications(ctx context.Context, cursor string, limit int) ([]Application, string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, "", err
+	}
+
+	var start []byte
+	if cursor != "" {
+		email, appliedDate, err := parseApplicationCursor(cursor)
+		if err != nil {
+			return nil, "", err
+		}
+		start = primaryKey(email, appliedDate)
+	}
+
+	var page []Application
+	var next string
+	err := b.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(applicationsBucket).Cursor()
+		k, v := c.First()
+		if start != nil {
+			k, v = c.Seek(start)
+			if bytes.Equal(k, start) {
+				k, v = c.Next()
+			}
+		}
+		for ; k != nil; k, v = c.Next() {
+			if len(page) == limit {
+				next = applicationCursor(page[len(page)-1])
+				return nil
+			}
+			var app Application
+			if err := json.Unmarshal(v, &app); err != nil {
+				return err
+			}
+			page = append(page, app)
+		}
+		return nil
+	})
+	return page, next, err
+}
+
+// ListUserApplications walks the user's github index backwards, so the most
+// recent application comes first
+func (b *BoltStore) ListUserApplications(ctx context.Context, user string, cursor string, limit int) ([]Application, string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, "", err
+	}
+
+	var start []byte
+	if cursor != "" {
+		email, appliedDate, err := parseApplicationCursor(cursor)
+		if err != nil {
+			return nil, "", err
+		}
+		start = indexKey(appliedDate, email)
+	}
+
+	var page []Application
+	var next string
+	err := b.db.View(func(tx *bolt.Tx) error {
+		index := tx.Bucket(githubBucket).Bucket([]byte(user))
+		if index == nil {
+			return nil
+		}
+		c := index.Cursor()
+		k, primary := c.Last()
+		if start != nil {
+			// Seek finds the first key at or after start, the page starts
+			// at the key before it
+			if k, _ = c.Seek(start); k == nil {
+				k, primary = c.Last()
+			} else {
+				k, primary = c.Prev()
+			}
+		}
+		for ; k != nil; k, primary = c.Prev() {
+			if len(page) == limit {
+				next = applicationCursor(page[len(page)-1])
+				return nil
+			}
+			record, err := getRecord(tx, primary)
+			if err != nil {
+				return err
+			}
+			if record == nil {
+				return fmt.Errorf("index for %s references missing application", user)
+			}
+			page = append(page, *record)
+		}
+		return nil
+	})
+	return page, next, err
+}
+
+func (b *BoltStore) CountRoleApplications(ctx context.Context, roles []Role) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return b.db.Update(func(tx *bolt.Tx) error {
+		for _, role := range roles {
+			count, err := getRoleCount(tx, role.ID)
+			if err != nil {
+				return err
+			}
+			if err := checkRoleRoom(role, count); err != nil {
+				return err
+			}
+			// Returning an error above rolls back the roles already counted
+			if err := putRoleCount(tx, role.ID, count+1); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (b *BoltStore) UncountRoleApplications(ctx context.Context, roleIDs []string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return b.db.Update(func(tx *bolt.Tx) error {
+		for _, id := range roleIDs {
+			count, err := getRoleCount(tx, id)
+			if err != nil {
+				return err
+			}
+			if err := putRoleCount(tx, id, count-1); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (b *BoltStore) RoleApplicationCounts(ctx context.Context, roleIDs []string) (map[string]int, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int, len(roleIDs))
+	err := b.db.View(func(tx *bolt.Tx) error {
+		for _, id := range roleIDs {
+			count, err := getRoleCount(tx, id)
+			if err != nil {
+				return err
+			}
+			counts[id] = count
+		}
+		return nil
+	})
+	return counts, err
+}
+
+func primaryKey(email string, appliedDate int64) []byte {
+	return []byte(email + "\x00" + strconv.FormatInt(appliedDate, 10))
+}
+
+// indexKey sorts lexically in applied date order
+func indexKey(appliedDate int64, email string) []byte {
+	return []byte(fmt.Sprintf("%020d\x00%s", appliedDate, email))
+}
+
+func getRecord(tx *bolt.Tx, key []byte) (*Application, error) {
+	data := tx.Bucket(applicationsBucket).Get(key)
+	if data == nil {
+		return nil, nil
+	}
+	var record Application
+	if err := json.Unmarshal(data, &record); err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+func putRecord(tx *bolt.Tx, record Application) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	key := primaryKey(record.Email, record.AppliedDate)
+	if err := tx.Bucket(applicationsBucket).Put(key, data); err != nil {
+		return err
+	}
+	index, err := tx.Bucket(githubBucket).CreateBucketIfNotExists([]byte(record.Github))
+	if err != nil {
+		return err
+	}
+	return index.Put(indexKey(record.AppliedDate, record.Email), key)
+}
+
+func deleteRecord(tx *bolt.Tx, record Application) error {
+	if err := tx.Bucket(applicationsBucket).Delete(primaryKey(record.Email, record.AppliedDate)); err != nil {
+		return err
+	}
+	index := tx.Bucket(githubBucket).Bucket([]byte(record.Github))
+	if index == nil {
+		return nil
+	}
+	return index.Delete(indexKey(record.AppliedDate, record.Email))
+}
+
+func getRoleCount(tx *bolt.Tx, roleID string) (int, error) {
+	data := tx.Bucket(roleCountsBucket).Get([]byte(roleID))
+	if data == nil {
+		return 0, nil
+	}
+	return strconv.Atoi(string(data))
+}
+
+func putRoleCount(tx *bolt.Tx, roleID string, count int) error {
+	return tx.Bucket(roleCountsBucket).Put([]byte(roleID), []byte(strconv.Itoa(count)))
+}
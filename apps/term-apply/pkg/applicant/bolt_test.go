@@ -0,0 +1,184 @@
+package applicant
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func newTestBoltStore(t *testing.T) *BoltStore {
+	t.Helper()
+	store, err := NewBoltStore(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("failed to open bolt store: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+func TestBoltStoreReturnsLatest(t *testing.T) {
+	store := newTestBoltStore(t)
+	store.PutApplication(context.Background(), Application{AppliedDate: 200, Github: "candy", Email: "b@date.com"})
+	store.PutApplication(context.Background(), Application{AppliedDate: 1000, Github: "candy", Email: "a@date.com"})
+	store.PutApplication(context.Background(), Application{AppliedDate: 3000, Github: "other", Email: "c@date.com"})
+
+	app, err := store.GetApplication(context.Background(), "candy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if app.AppliedDate != 1000 {
+		t.Fatalf("expected latest application, got %+v", app)
+	}
+
+	if _, err := store.GetApplication(context.Background(), "nobody"); err == nil {
+		t.Fatalf("expected an empty result error")
+	} else if _, ok := err.(*emptyResultError); !ok {
+		t.Fatalf("expected an empty result error, got %T", err)
+	}
+}
+
+func TestBoltStoreUpdateInPlace(t *testing.T) {
+	store := newTestBoltStore(t)
+	store.PutApplication(context.Background(), Application{
+		AppliedDate: 100,
+		Github:      "candy",
+		Name:        "Candy Date",
+		Email:       "candy@date.com",
+		RoleApplied: "Software Engineer",
+		Rejected:    true,
+	})
+
+	err := store.UpdateApplication(context.Background(), Application{
+		AppliedDate: 100,
+		Github:      "candy",
+		Name:        "Candy Dated",
+		Email:       "candy@date.com",
+		RoleApplied: "Senior Software Engineer",
+		Version:     1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	app, _ := store.GetApplication(context.Background(), "candy")
+	if app.Name != "Candy Dated" || app.RoleApplied != "Senior Software Engineer" {
+		t.Fatalf("application was not updated: %+v", app)
+	}
+	if !app.Rejected {
+		t.Fatalf("fields not set by the candidate should be preserved")
+	}
+}
+
+func TestBoltStoreRecreate(t *testing.T) {
+	store := newTestBoltStore(t)
+	store.PutApplication(context.Background(), Application{
+		AppliedDate: 100,
+		Github:      "candy",
+		Name:        "Candy Date",
+		Email:       "candy@date.com",
+		RoleApplied: "Software Engineer",
+	})
+
+	updated := Application{
+		AppliedDate: 100,
+		Github:      "candy",
+		Name:        "Candy Date",
+		Email:       "candy@example.com",
+		RoleApplied: "Software Engineer",
+		Version:     1,
+	}
+	if err := store.RecreateApplication(context.Background(), updated, "candy@date.com"); err != nil {
+		t.Fatal(err)
+	}
+	updated.Version = 2
+
+	app, _ := store.GetApplication(context.Background(), "candy")
+	if !reflect.DeepEqual(app, updated) {
+		t.Fatalf("expected recreated application, got %+v", app)
+	}
+
+	// The original record no longer exists to recreate from
+	if err := store.RecreateApplication(context.Background(), updated, "candy@date.com"); err == nil {
+		t.Fatalf("expected missing record error")
+	}
+}
+
+func TestAddApplicantWithBoltStore(t *testing.T) {
+	store := newTestBoltStore(t)
+	am := newTestManager(t, store)
+
+	addAndWait(t, am, "candy", "Candy Date", "candy@date.com", "senior-software-engineer")
+	addAndWait(t, am, "candy", "Candy Date", "candy@example.com", "senior-software-engineer")
+
+	app, err := store.GetApplication(context.Background(), "candy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if app.Email != "candy@example.com" {
+		t.Fatalf("expected email change to be recorded: %+v", app)
+	}
+}
+
+func TestBoltStoreRejectsStaleVersion(t *testing.T) {
+	store := newTestBoltStore(t)
+	app := Application{AppliedDate: 100, Github: "candy", Name: "Candy Date", Email: "candy@date.com"}
+	store.PutApplication(context.Background(), app)
+
+	if err := store.PutApplication(context.Background(), app); !errors.As(err, new(*VersionConflictError)) {
+		t.Fatalf("expected a version conflict for a duplicate put, got %v", err)
+	}
+
+	app.Version = 1
+	app.Name = "Candy Dated"
+	if err := store.UpdateApplication(context.Background(), app); err != nil {
+		t.Fatal(err)
+	}
+	app.Name = "Candy Updated"
+	if err := store.UpdateApplication(context.Background(), app); !errors.As(err, new(*VersionConflictError)) {
+		t.Fatalf("expected a version conflict for a stale update, got %v", err)
+	}
+
+	stored, _ := store.GetApplication(context.Background(), "candy")
+	if stored.Name != "Candy Dated" || stored.Version != 2 {
+		t.Fatalf("stale writes should not have been applied: %+v", stored)
+	}
+
+	missing := Application{AppliedDate: 200, Github: "dandy", Email: "dandy@date.com"}
+	if err := store.UpdateApplication(context.Background(), missing); !errors.As(err, new(*ApplicationNotFoundError)) {
+		t.Fatalf("expected an update of a missing application to be refused, got %v", err)
+	}
+	if _, err := store.GetApplication(context.Background(), "dandy"); err == nil {
+		t.Fatalf("an update should not create an application")
+	}
+}
+
+func TestBoltStoreRoleCounts(t *testing.T) {
+	store := newTestBoltStore(t)
+	swe := Role{ID: "swe", Title: "Software Engineer", MaxApplications: 1}
+	sre := Role{ID: "sre", Title: "Site Reliability Engineer"}
+
+	if err := store.CountRoleApplications(context.Background(), []Role{swe, sre}); err != nil {
+		t.Fatal(err)
+	}
+	// A full role counts none of the application's roles
+	var refused *RoleClosedError
+	if err := store.CountRoleApplications(context.Background(), []Role{sre, swe}); !errors.As(err, &refused) || refused.Role.ID != "swe" {
+		t.Fatalf("expected the full role to be refused, got %v", err)
+	}
+	counts, err := store.RoleApplicationCounts(context.Background(), []string{"swe", "sre", "pm"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(counts, map[string]int{"swe": 1, "sre": 1, "pm": 0}) {
+		t.Fatalf("unexpected counts %v", counts)
+	}
+
+	if err := store.UncountRoleApplications(context.Background(), []string{"swe"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.CountRoleApplications(context.Background(), []Role{swe}); err != nil {
+		t.Fatalf("expected the role to have room once uncounted: %v", err)
+	}
+}
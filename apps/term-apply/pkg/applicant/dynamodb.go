@@ -1,12 +1,15 @@
 package applicant
 
 import (
+	"context"
 	"fmt"
 	"log"
+	"strconv"
 
 	"github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
 )
 
 /*
@@ -15,19 +18,62 @@ The relevant expected schema for the DynamoDB table is as follows:
 
 > applied_date: unix time - number - Sort DDB Key <br>
 > email: candy@date.com - string - Primary/Partition DDB Key <br>
-> github: candydate100 - string - Secondary Global Index <br>
 > name: Candy Date - string <br>
+> github: candydate100 - string - Global Secondary Index, with applied_date as its sort key <br>
 > role_applied: sr. software engineer - string <br>
+> role_id: senior-software-engineer - string - stable ID of the role in the role catalog <br>
+> roles: List - every role applied for, role_applied and role_id are the first <br>
+>   {id: string, title: string, role_override: string, rejected: bool, rejected_date: number} <br>
+> answers: Map - screening question ID to the candidate's answer <br>
+> role_override: string - in the case were their role is different otherwise null <br>
+> resume_review: bool - Resume passed or fail the review <br>
+> resume_review_date: number - resume reviewed date <br>
+> interviews: Map <br>
+>   1: {date: number, pass: bool, notes: binary, interviewer: string, rating: number, competencies: Map} <br>
+> ignore_workflow: bool - ignore from automation workflow <br>
+> flags: Map of workflow rule name to number <br>
 > offer_given: bool <br>
+> offer_date: number <br>
+> offer_accepted: bool <br>
+> offer_accepted_date: number <br>
 > rejected: bool <br>
+> rejected_date: number <br>
+> rejected_msg_override: binary - Message custom to applicant <br>
+> state: applied, resume_reviewed, interviewing, offer, accepted, declined, rejected or withdrawn - string <br>
+> state_history: List of {state: string, date: number} <br>
+> version: number - incremented on every write by term-apply <br>
+
+Items are converted to and from Application with dynamodbattribute, so the
+struct tags on Application are the source of truth for attribute names.
 
 term-apply users have the ability to modify their email after submitting
 an application. If this happens, the existing record will be deleted and
 recreated with updated fields using a transaction to ensure atomic changes.
 Other item edits are done in place.
 
+Every write is conditioned on the version that was read, so a concurrent
+edit (for example by staff) fails the write with a VersionConflictError
+instead of being overwritten. Items written before versioning are treated as
+version 0. Updates are also conditioned on the item existing, since
+UpdateItem would otherwise create it, and fail with an
+ApplicationNotFoundError if it is gone.
+
+Applications received for each role are counted in items of the same table,
+keyed by
+
+> email: count#<role id> <br>
+> applied_date: 0 <br>
+> role_count: applications counted for the role - number <br>
+
+An application's roles are counted in one transaction, with each increment
+conditioned on the role being under its limit, so concurrent applications
+cannot take a role over it. Count items have no github attribute, so they
+never appear in the github index.
+
 */
 
+const roleCountKeyPrefix = "count#"
+
 type emptyResultError struct {
 	user string
 }
@@ -42,168 +88,235 @@ func (err *emptyResultError) Error() string {
 	return fmt.Sprintf("No uploads found for user %s", err.user)
 }
 
-func applicationFromItem(item map[string]*dynamodb.AttributeValue) application {
-	var app application
+// DynamoDBStore is an ApplicationStore backed by a DynamoDB table and a
+// global secondary index on the github attribute.
+type DynamoDBStore struct {
+	svc   dynamodbiface.DynamoDBAPI
+	table string
+	index string
+}
 
-	appliedDate, exists := item["applied_date"]
-	if exists {
-		app.appliedDate = *appliedDate.N
-	}
-	github, exists := item["github"]
-	if exists {
-		app.github = *github.S
+func NewDynamoDBStore(svc dynamodbiface.DynamoDBAPI, table, index string) *DynamoDBStore {
+	return &DynamoDBStore{
+		svc:   svc,
+		table: table,
+		index: index,
 	}
-	email, exists := item["email"]
-	if exists {
-		app.email = *email.S
-	}
-	name, exists := item["name"]
-	if exists {
-		app.name = *name.S
-	}
-	roleApplied, exists := item["role_applied"]
-	if exists {
-		app.roleApplied = *roleApplied.S
-	}
-	offferGiven, exists := item["offer_given"]
-	if exists {
-		app.offerGiven = *offferGiven.BOOL
-	}
-	rejected, exists := item["rejected"]
-	if exists {
-		app.rejected = *rejected.BOOL
-	}
-
-	return app
 }
 
-// Returns the provided user's most recent application
-func GetApplication(user, table, index string) (application, error) {
-	sess, err := session.NewSessionWithOptions(session.Options{
-		SharedConfigState: session.SharedConfigEnable,
+// CheckIndex verifies that the github index sorts on applied_date. Latest
+// application lookups and history paging read the index newest first, and an
+// index without that sort key would silently return applications in an
+// arbitrary order.
+func (d *DynamoDBStore) CheckIndex(ctx context.Context) error {
+	result, err := d.svc.DescribeTableWithContext(ctx, &dynamodb.DescribeTableInput{
+		TableName: aws.String(d.table),
 	})
 	if err != nil {
-		return application{}, err
+		return err
+	}
+	for _, index := range result.Table.GlobalSecondaryIndexes {
+		if aws.StringValue(index.IndexName) != d.index {
+			continue
+		}
+		keys := map[string]string{}
+		for _, key := range index.KeySchema {
+			keys[aws.StringValue(key.KeyType)] = aws.StringValue(key.AttributeName)
+		}
+		if keys[dynamodb.KeyTypeHash] != "github" || keys[dynamodb.KeyTypeRange] != "applied_date" {
+			return fmt.Errorf("index %s of table %s must have github as its partition key and applied_date as its sort key", d.index, d.table)
+		}
+		return nil
 	}
+	return fmt.Errorf("table %s has no index %s", d.table, d.index)
+}
 
-	svc := dynamodb.New(sess)
+func applicationFromItem(item map[string]*dynamodb.AttributeValue) (Application, error) {
+	var app Application
+	err := dynamodbattribute.UnmarshalMap(item, &app)
+	return app, err
+}
 
-	result, err := svc.Query(&dynamodb.QueryInput{
-		TableName:              aws.String(table),
-		IndexName:              aws.String(index),
+// Returns the provided user's most recent application
+func (d *DynamoDBStore) GetApplication(ctx context.Context, user string) (Application, error) {
+	result, err := d.svc.QueryWithContext(ctx, &dynamodb.QueryInput{
+		TableName:              aws.String(d.table),
+		IndexName:              aws.String(d.index),
 		KeyConditionExpression: aws.String("github = :github"),
 		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
 			":github": {S: aws.String(user)},
 		},
+		// The index sorts on applied_date, so the first item is the latest
+		ScanIndexForward: aws.Bool(false),
+		Limit:            aws.Int64(1),
 	})
 	if err != nil {
-		return application{}, err
+		return Application{}, err
 	}
 
 	if len(result.Items) == 0 {
 		log.Printf("No applications found for %s", user)
-		return application{}, newEmptyResult(user)
+		return Application{}, newEmptyResult(user)
 	}
 
-	app := applicationFromItem(result.Items[len(result.Items)-1])
-	return app, nil
+	return applicationFromItem(result.Items[0])
 }
 
-func PutApplication(app application, table string) error {
-	sess, err := session.NewSessionWithOptions(session.Options{
-		SharedConfigState: session.SharedConfigEnable,
-	})
+// ListUserApplications queries the github index most recent first. The
+// cursor holds the table and index keys of the last item of the previous
+// page, which is everything DynamoDB needs to resume the query.
+func (d *DynamoDBStore) ListUserApplications(ctx context.Context, user string, cursor string, limit int) ([]Application, string, error) {
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(d.table),
+		IndexName:              aws.String(d.index),
+		KeyConditionExpression: aws.String("github = :github"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":github": {S: aws.String(user)},
+		},
+		ScanIndexForward: aws.Bool(false),
+		Limit:            aws.Int64(int64(limit)),
+	}
+	if cursor != "" {
+		email, appliedDate, err := parseApplicationCursor(cursor)
+		if err != nil {
+			return nil, "", err
+		}
+		input.ExclusiveStartKey = map[string]*dynamodb.AttributeValue{
+			"github":       {S: aws.String(user)},
+			"applied_date": {N: aws.String(strconv.FormatInt(appliedDate, 10))},
+			"email":        {S: aws.String(email)},
+		}
+	}
+
+	result, err := d.svc.QueryWithContext(ctx, input)
 	if err != nil {
-		return err
+		return nil, "", err
 	}
 
-	svc := dynamodb.New(sess)
+	page := make([]Application, 0, len(result.Items))
+	for _, item := range result.Items {
+		app, err := applicationFromItem(item)
+		if err != nil {
+			return nil, "", err
+		}
+		page = append(page, app)
+	}
+	if len(result.LastEvaluatedKey) == 0 || len(page) == 0 {
+		return page, "", nil
+	}
+	return page, applicationCursor(page[len(page)-1]), nil
+}
 
-	_, err = svc.PutItem(&dynamodb.PutItemInput{
-		TableName: aws.String(table),
-		Item: map[string]*dynamodb.AttributeValue{
-			"applied_date": {
-				N: &app.appliedDate,
-			},
-			"github": {
-				S: &app.github,
-			},
-			"name": {
-				S: &app.name,
-			},
-			"email": {
-				S: &app.email,
-			},
-			"role_applied": {
-				S: &app.roleApplied,
-			},
-		},
-	})
+func (d *DynamoDBStore) PutApplication(ctx context.Context, app Application) error {
+	app.Version = 1
+	item, err := dynamodbattribute.MarshalMap(app)
 	if err != nil {
 		return err
 	}
 
+	_, err = d.svc.PutItemWithContext(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(d.table),
+		Item:      item,
+		// Never overwrite an existing application
+		ConditionExpression: aws.String("attribute_not_exists(email)"),
+	})
+	if isConditionalCheckFailed(err) {
+		return newVersionConflict(app)
+	} else if err != nil {
+		return err
+	}
+
 	return nil
 }
 
-func UpdateApplication(app application, table string) error {
-	sess, err := session.NewSessionWithOptions(session.Options{
-		SharedConfigState: session.SharedConfigEnable,
-	})
+func (d *DynamoDBStore) UpdateApplication(ctx context.Context, app Application) error {
+	roles, err := dynamodbattribute.Marshal(app.Roles)
 	if err != nil {
 		return err
 	}
-
-	svc := dynamodb.New(sess)
-
-	_, err = svc.UpdateItem(&dynamodb.UpdateItemInput{
-		TableName: aws.String(table),
+	answers, err := dynamodbattribute.Marshal(app.Answers)
+	if err != nil {
+		return err
+	}
+	_, err = d.svc.UpdateItemWithContext(ctx, &dynamodb.UpdateItemInput{
+		TableName: aws.String(d.table),
 		Key: map[string]*dynamodb.AttributeValue{
 			"applied_date": {
-				N: &app.appliedDate,
+				N: aws.String(strconv.FormatInt(app.AppliedDate, 10)),
 			},
 			"email": {
-				S: &app.email,
+				S: &app.Email,
 			},
 		},
-		UpdateExpression: aws.String("SET #n = :n, #r = :r"),
+		UpdateExpression: aws.String("SET #n = :n, #r = :r, #i = :i, #l = :l, #q = :q, #v = :next"),
+		// UpdateItem would otherwise create a missing item
+		ConditionExpression: aws.String("attribute_exists(email) AND " + versionCondition(app.Version)),
 		ExpressionAttributeNames: map[string]*string{
 			"#n": aws.String("name"),
 			"#r": aws.String("role_applied"),
+			"#i": aws.String("role_id"),
+			"#l": aws.String("roles"),
+			"#q": aws.String("answers"),
+			"#v": aws.String("version"),
 		},
-		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+		ExpressionAttributeValues: withVersionValue(app.Version, map[string]*dynamodb.AttributeValue{
 			":n": {
-				S: &app.name,
+				S: &app.Name,
 			},
 			":r": {
-				S: &app.roleApplied,
+				S: &app.RoleApplied,
 			},
-		},
+			":i": {
+				S: &app.RoleID,
+			},
+			":l": roles,
+			":q": answers,
+			":next": {
+				N: aws.String(strconv.FormatInt(app.Version+1, 10)),
+			},
+		}),
 	})
-	if err != nil {
+	if isConditionalCheckFailed(err) {
+		return d.conditionFailed(ctx, app)
+	} else if err != nil {
 		return err
 	}
 
 	return nil
 }
 
-func RecreateApplication(app application, prevEmail, table string) error {
-	// Create DynamoDB Session
-	sess, err := session.NewSessionWithOptions(session.Options{
-		SharedConfigState: session.SharedConfigEnable,
+// conditionFailed works out why a write conditioned on app existing at its
+// version failed: either the item is gone or it was modified
+func (d *DynamoDBStore) conditionFailed(ctx context.Context, app Application) error {
+	result, err := d.svc.GetItemWithContext(ctx, &dynamodb.GetItemInput{
+		TableName:            aws.String(d.table),
+		Key:                  primaryItemKey(app.Email, app.AppliedDate),
+		ConsistentRead:       aws.Bool(true),
+		ProjectionExpression: aws.String("email"),
 	})
 	if err != nil {
 		return err
 	}
+	if len(result.Item) == 0 {
+		return &ApplicationNotFoundError{Email: app.Email, AppliedDate: app.AppliedDate}
+	}
+	return newVersionConflict(app)
+}
 
-	svc := dynamodb.New(sess)
+func primaryItemKey(email string, appliedDate int64) map[string]*dynamodb.AttributeValue {
+	return map[string]*dynamodb.AttributeValue{
+		"applied_date": {N: aws.String(strconv.FormatInt(appliedDate, 10))},
+		"email":        {S: aws.String(email)},
+	}
+}
 
+func (d *DynamoDBStore) RecreateApplication(ctx context.Context, app Application, prevEmail string) error {
 	// Query record to update to ensure all unchanged values are preserved
-	result, err := svc.Query(&dynamodb.QueryInput{
-		TableName:              aws.String(table),
+	result, err := d.svc.QueryWithContext(ctx, &dynamodb.QueryInput{
+		TableName:              aws.String(d.table),
 		KeyConditionExpression: aws.String("applied_date = :a and email = :e"),
 		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
-			":a": {N: aws.String(app.appliedDate)},
+			":a": {N: aws.String(strconv.FormatInt(app.AppliedDate, 10))},
 			":e": {S: aws.String(prevEmail)},
 		},
 	})
@@ -212,53 +325,254 @@ func RecreateApplication(app application, prevEmail, table string) error {
 	}
 
 	if len(result.Items) == 0 {
-		return fmt.Errorf("expected record not found: %s (applied at %s)", prevEmail, app.appliedDate)
+		return &ApplicationNotFoundError{Email: prevEmail, AppliedDate: app.AppliedDate}
 	}
 
-	record := result.Items[0]
-
-	// Update values in record
-	record["email"] = &dynamodb.AttributeValue{
-		S: aws.String(app.email),
+	current, err := applicationFromItem(result.Items[0])
+	if err != nil {
+		return err
 	}
-	record["name"] = &dynamodb.AttributeValue{
-		S: aws.String(app.name),
+	if current.Version != app.Version {
+		return newVersionConflict(app)
 	}
-	record["role_applied"] = &dynamodb.AttributeValue{
-		S: aws.String(app.roleApplied),
+
+	// Preserve fields the candidate cannot change
+	current.setCandidateFields(app)
+	current.Version++
+	record, err := dynamodbattribute.MarshalMap(current)
+	if err != nil {
+		return err
 	}
 
 	items := []*dynamodb.TransactWriteItem{
 		// Queue deleting original record
 		&dynamodb.TransactWriteItem{
 			Delete: &dynamodb.Delete{
-				TableName: aws.String(table),
+				TableName: aws.String(d.table),
 				Key: map[string]*dynamodb.AttributeValue{
 					"applied_date": {
-						N: aws.String(app.appliedDate),
+						N: aws.String(strconv.FormatInt(app.AppliedDate, 10)),
 					},
 					"email": {
 						S: aws.String(prevEmail),
 					},
 				},
+				ConditionExpression: aws.String(versionCondition(app.Version)),
+				ExpressionAttributeNames: map[string]*string{
+					"#v": aws.String("version"),
+				},
+				ExpressionAttributeValues: withVersionValue(app.Version, nil),
 			},
 		},
 
 		// Queue recreating record with updated values
 		&dynamodb.TransactWriteItem{
 			Put: &dynamodb.Put{
-				TableName: aws.String(table),
-				Item:      record,
+				TableName:           aws.String(d.table),
+				Item:                record,
+				ConditionExpression: aws.String("attribute_not_exists(email)"),
 			},
 		},
 	}
 
-	_, err = svc.TransactWriteItems(&dynamodb.TransactWriteItemsInput{
+	_, err = d.svc.TransactWriteItemsWithContext(ctx, &dynamodb.TransactWriteItemsInput{
 		TransactItems: items,
 	})
+	if isTransactionConditionFailed(err) {
+		return newVersionConflict(app)
+	} else if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (d *DynamoDBStore) SaveApplication(ctx context.Context, app Application) error {
+	expected := app
+	app.Version++
+	item, err := dynamodbattribute.MarshalMap(app)
 	if err != nil {
 		return err
 	}
 
+	_, err = d.svc.PutItemWithContext(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(d.table),
+		Item:      item,
+		// Only overwrite the application that was read
+		ConditionExpression: aws.String("attribute_exists(email) AND " + versionCondition(expected.Version)),
+		ExpressionAttributeNames: map[string]*string{
+			"#v": aws.String("version"),
+		},
+		ExpressionAttributeValues: withVersionValue(expected.Version, nil),
+	})
+	if isConditionalCheckFailed(err) {
+		return d.conditionFailed(ctx, expected)
+	} else if err != nil {
+		return err
+	}
+
 	return nil
 }
+
+// ListApplications scans the table a page at a time. Lease and role count
+// items are filtered out, so a scan is repeated until limit applications are found or
+// the table is exhausted.
+func (d *DynamoDBStore) ListApplications(ctx context.Context, cursor string, limit int) ([]Application, string, error) {
+	var start map[string]*dynamodb.AttributeValue
+	if cursor != "" {
+		email, appliedDate, err := parseApplicationCursor(cursor)
+		if err != nil {
+			return nil, "", err
+		}
+		start = map[string]*dynamodb.AttributeValue{
+			"applied_date": {N: aws.String(strconv.FormatInt(appliedDate, 10))},
+			"email":        {S: aws.String(email)},
+		}
+	}
+
+	var page []Application
+	for {
+		result, err := d.svc.ScanWithContext(ctx, &dynamodb.ScanInput{
+			TableName:         aws.String(d.table),
+			Limit:             aws.Int64(int64(limit - len(page))),
+			ExclusiveStartKey: start,
+			FilterExpression:  aws.String("attribute_exists(github)"),
+		})
+		if err != nil {
+			return nil, "", err
+		}
+		for _, item := range result.Items {
+			app, err := applicationFromItem(item)
+			if err != nil {
+				return nil, "", err
+			}
+			page = append(page, app)
+		}
+
+		if len(result.LastEvaluatedKey) == 0 {
+			return page, "", nil
+		}
+		if len(page) == limit {
+			return page, applicationCursor(page[len(page)-1]), nil
+		}
+		start = result.LastEvaluatedKey
+	}
+}
+
+func (d *DynamoDBStore) CountRoleApplications(ctx context.Context, roles []Role) error {
+	if len(roles) == 0 {
+		return nil
+	}
+	items := make([]*dynamodb.TransactWriteItem, len(roles))
+	for i, role := range roles {
+		update := &dynamodb.Update{
+			TableName:        aws.String(d.table),
+			Key:              roleCountItemKey(role.ID),
+			UpdateExpression: aws.String("ADD role_count :one"),
+			ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+				":one": {N: aws.String("1")},
+			},
+		}
+		if role.MaxApplications > 0 {
+			update.ConditionExpression = aws.String("attribute_not_exists(role_count) OR role_count < :max")
+			update.ExpressionAttributeValues[":max"] = &dynamodb.AttributeValue{N: aws.String(strconv.Itoa(role.MaxApplications))}
+		}
+		items[i] = &dynamodb.TransactWriteItem{Update: update}
+	}
+
+	_, err := d.svc.TransactWriteItemsWithContext(ctx, &dynamodb.TransactWriteItemsInput{
+		TransactItems: items,
+	})
+	if canceled, ok := err.(*dynamodb.TransactionCanceledException); ok {
+		// Reasons are listed in the order of the transaction's items
+		for i, reason := range canceled.CancellationReasons {
+			if aws.StringValue(reason.Code) == "ConditionalCheckFailed" && i < len(roles) {
+				return &RoleClosedError{Role: roles[i], Reason: reasonFull}
+			}
+		}
+	}
+	return err
+}
+
+// UncountRoleApplications takes back the counts in one transaction, so a
+// failure leaves every count as it was
+func (d *DynamoDBStore) UncountRoleApplications(ctx context.Context, roleIDs []string) error {
+	if len(roleIDs) == 0 {
+		return nil
+	}
+	items := make([]*dynamodb.TransactWriteItem, len(roleIDs))
+	for i, id := range roleIDs {
+		items[i] = &dynamodb.TransactWriteItem{Update: &dynamodb.Update{
+			TableName:        aws.String(d.table),
+			Key:              roleCountItemKey(id),
+			UpdateExpression: aws.String("ADD role_count :minus"),
+			ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+				":minus": {N: aws.String("-1")},
+			},
+		}}
+	}
+	_, err := d.svc.TransactWriteItemsWithContext(ctx, &dynamodb.TransactWriteItemsInput{
+		TransactItems: items,
+	})
+	return err
+}
+
+func (d *DynamoDBStore) RoleApplicationCounts(ctx context.Context, roleIDs []string) (map[string]int, error) {
+	counts := make(map[string]int, len(roleIDs))
+	for _, id := range roleIDs {
+		result, err := d.svc.GetItemWithContext(ctx, &dynamodb.GetItemInput{
+			TableName:            aws.String(d.table),
+			Key:                  roleCountItemKey(id),
+			ConsistentRead:       aws.Bool(true), // a role that just filled is not listed as open
+			ProjectionExpression: aws.String("role_count"),
+		})
+		if err != nil {
+			return nil, err
+		}
+		if value, ok := result.Item["role_count"]; ok {
+			if counts[id], err = strconv.Atoi(aws.StringValue(value.N)); err != nil {
+				return nil, fmt.Errorf("invalid count for role %s: %w", id, err)
+			}
+		}
+	}
+	return counts, nil
+}
+
+func roleCountItemKey(roleID string) map[string]*dynamodb.AttributeValue {
+	return primaryItemKey(roleCountKeyPrefix+roleID, 0)
+}
+
+// versionCondition matches items still at version. Conditions reference the
+// version attribute as #v.
+func versionCondition(version int64) string {
+	if version == 0 {
+		return "attribute_not_exists(#v)"
+	}
+	return "#v = :v"
+}
+
+// withVersionValue adds the :v value used by versionCondition, since
+// DynamoDB rejects expression values that are never referenced
+func withVersionValue(version int64, values map[string]*dynamodb.AttributeValue) map[string]*dynamodb.AttributeValue {
+	if version == 0 {
+		return values
+	}
+	if values == nil {
+		values = map[string]*dynamodb.AttributeValue{}
+	}
+	values[":v"] = &dynamodb.AttributeValue{N: aws.String(strconv.FormatInt(version, 10))}
+	return values
+}
+
+func isTransactionConditionFailed(err error) bool {
+	canceled, ok := err.(*dynamodb.TransactionCanceledException)
+	if !ok {
+		return false
+	}
+	for _, reason := range canceled.CancellationReasons {
+		if aws.StringValue(reason.Code) == "ConditionalCheckFailed" {
+			return true
+		}
+	}
+	return false
+}
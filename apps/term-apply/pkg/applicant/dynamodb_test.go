@@ -0,0 +1,356 @@
+package applicant
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+)
+
+// fakeApplicationTable implements just enough of DynamoDB to check the
+// conditions DynamoDBStore puts on its writes
+type fakeApplicationTable struct {
+	dynamodbiface.DynamoDBAPI
+	lock      sync.Mutex
+	items     map[string]Application
+	counts    map[string]int               // role count items by role ID
+	indexKeys []*dynamodb.KeySchemaElement // key schema of github-index
+}
+
+func newFakeApplicationTable(apps ...Application) *fakeApplicationTable {
+	f := &fakeApplicationTable{items: map[string]Application{}, counts: map[string]int{}}
+	for _, app := range apps {
+		f.items[applicationCursor(app)] = app
+	}
+	return f
+}
+
+func fakeItemKey(key map[string]*dynamodb.AttributeValue) string {
+	return aws.StringValue(key["applied_date"].N) + ":" + aws.StringValue(key["email"].S)
+}
+
+// fakeRoleID returns the role ID of a role count item key
+func fakeRoleID(key map[string]*dynamodb.AttributeValue) string {
+	return strings.TrimPrefix(aws.StringValue(key["email"].S), roleCountKeyPrefix)
+}
+
+func isFakeCountKey(key map[string]*dynamodb.AttributeValue) bool {
+	return strings.HasPrefix(aws.StringValue(key["email"].S), roleCountKeyPrefix)
+}
+
+// updateCount returns an ADD to a role count item for a transaction to
+// apply, or reports that its limit condition failed. Callers must hold the
+// lock.
+func (f *fakeApplicationTable) updateCount(key map[string]*dynamodb.AttributeValue, condition *string, values map[string]*dynamodb.AttributeValue) (func(), bool) {
+	id := fakeRoleID(key)
+	if max, ok := values[":max"]; ok && condition != nil {
+		limit, _ := strconv.Atoi(aws.StringValue(max.N))
+		if f.counts[id] >= limit {
+			return nil, false
+		}
+	}
+	delta := 1
+	if _, ok := values[":minus"]; ok {
+		delta = -1
+	}
+	return func() { f.counts[id] += delta }, true
+}
+
+func (f *fakeApplicationTable) TransactWriteItemsWithContext(_ aws.Context, input *dynamodb.TransactWriteItemsInput, _ ...request.Option) (*dynamodb.TransactWriteItemsOutput, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	var apply []func()
+	canceled := &dynamodb.TransactionCanceledException{}
+	failed := false
+	for _, item := range input.TransactItems {
+		update := item.Update
+		if update == nil || !isFakeCountKey(update.Key) {
+			return nil, errors.New("fake table only supports role count transactions")
+		}
+		change, ok := f.updateCount(update.Key, update.ConditionExpression, update.ExpressionAttributeValues)
+		code := "None"
+		if !ok {
+			code, failed = "ConditionalCheckFailed", true
+		}
+		canceled.CancellationReasons = append(canceled.CancellationReasons, &dynamodb.CancellationReason{Code: aws.String(code)})
+		apply = append(apply, change)
+	}
+	if failed {
+		return nil, canceled
+	}
+	for _, change := range apply {
+		change()
+	}
+	return &dynamodb.TransactWriteItemsOutput{}, nil
+}
+
+func (f *fakeApplicationTable) UpdateItemWithContext(_ aws.Context, input *dynamodb.UpdateItemInput, _ ...request.Option) (*dynamodb.UpdateItemOutput, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	key := fakeItemKey(input.Key)
+	existing, ok := f.items[key]
+	condition := aws.StringValue(input.ConditionExpression)
+	if !ok && strings.Contains(condition, "attribute_exists(email)") {
+		return nil, conditionFailed()
+	}
+	expected := int64(0)
+	if v, ok := input.ExpressionAttributeValues[":v"]; ok {
+		expected, _ = strconv.ParseInt(aws.StringValue(v.N), 10, 64)
+	}
+	if ok && existing.Version != expected {
+		return nil, conditionFailed()
+	}
+	if !ok {
+		// UpdateItem upserts when nothing stops it
+		existing = Application{Email: aws.StringValue(input.Key["email"].S)}
+	}
+	existing.Name = aws.StringValue(input.ExpressionAttributeValues[":n"].S)
+	existing.Version++
+	f.items[key] = existing
+	return &dynamodb.UpdateItemOutput{}, nil
+}
+
+func (f *fakeApplicationTable) GetItemWithContext(_ aws.Context, input *dynamodb.GetItemInput, _ ...request.Option) (*dynamodb.GetItemOutput, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if isFakeCountKey(input.Key) {
+		if !aws.BoolValue(input.ConsistentRead) {
+			return nil, errors.New("role counts must be read consistently")
+		}
+		count, ok := f.counts[fakeRoleID(input.Key)]
+		if !ok {
+			return &dynamodb.GetItemOutput{}, nil
+		}
+		return &dynamodb.GetItemOutput{Item: map[string]*dynamodb.AttributeValue{
+			"role_count": {N: aws.String(strconv.Itoa(count))},
+		}}, nil
+	}
+
+	app, ok := f.items[fakeItemKey(input.Key)]
+	if !ok {
+		return &dynamodb.GetItemOutput{}, nil
+	}
+	item, err := dynamodbattribute.MarshalMap(app)
+	return &dynamodb.GetItemOutput{Item: item}, err
+}
+
+func (f *fakeApplicationTable) DescribeTableWithContext(_ aws.Context, input *dynamodb.DescribeTableInput, _ ...request.Option) (*dynamodb.DescribeTableOutput, error) {
+	table := &dynamodb.TableDescription{TableName: input.TableName}
+	if f.indexKeys != nil {
+		table.GlobalSecondaryIndexes = []*dynamodb.GlobalSecondaryIndexDescription{
+			{IndexName: aws.String("github-index"), KeySchema: f.indexKeys},
+		}
+	}
+	return &dynamodb.DescribeTableOutput{Table: table}, nil
+}
+
+// QueryWithContext queries the github index, which sorts on applied_date.
+// Like DynamoDB, a query that stops at its limit returns a LastEvaluatedKey
+// even when no items are left.
+func (f *fakeApplicationTable) QueryWithContext(_ aws.Context, input *dynamodb.QueryInput, _ ...request.Option) (*dynamodb.QueryOutput, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	github := aws.StringValue(input.ExpressionAttributeValues[":github"].S)
+	var apps []Application
+	for _, app := range f.items {
+		if app.Github == github {
+			apps = append(apps, app)
+		}
+	}
+	sort.Slice(apps, func(i, j int) bool { return newerApplication(apps[i], apps[j]) })
+	if aws.BoolValue(input.ScanIndexForward) {
+		for i, j := 0, len(apps)-1; i < j; i, j = i+1, j-1 {
+			apps[i], apps[j] = apps[j], apps[i]
+		}
+	}
+	if start := input.ExclusiveStartKey; start != nil {
+		key := fakeItemKey(start)
+		for i, app := range apps {
+			if applicationCursor(app) == key {
+				apps = apps[i+1:]
+				break
+			}
+		}
+	}
+
+	output := &dynamodb.QueryOutput{}
+	limit := int(aws.Int64Value(input.Limit))
+	for _, app := range apps {
+		if len(output.Items) == limit {
+			break
+		}
+		item, err := dynamodbattribute.MarshalMap(app)
+		if err != nil {
+			return nil, err
+		}
+		output.Items = append(output.Items, item)
+	}
+	if len(output.Items) == limit && limit > 0 {
+		last := output.Items[len(output.Items)-1]
+		output.LastEvaluatedKey = map[string]*dynamodb.AttributeValue{
+			"github":       last["github"],
+			"applied_date": last["applied_date"],
+			"email":        last["email"],
+		}
+	}
+	return output, nil
+}
+
+func TestApplicationCursorRoundTrip(t *testing.T) {
+	app := Application{AppliedDate: 1650000000, Email: "candy:date@date.com"}
+	email, appliedDate, err := parseApplicationCursor(applicationCursor(app))
+	if err != nil || email != app.Email || appliedDate != app.AppliedDate {
+		t.Fatalf("expected the cursor to round trip, got %q %d %v", email, appliedDate, err)
+	}
+	for _, cursor := range []string{"candy@date.com", "yesterday:candy@date.com", ":"} {
+		if _, _, err := parseApplicationCursor(cursor); err == nil {
+			t.Errorf("expected cursor %q to be refused", cursor)
+		}
+	}
+}
+
+func TestDynamoDBListUserApplicationsPages(t *testing.T) {
+	table := newFakeApplicationTable(
+		Application{AppliedDate: 100, Github: "candy", Email: "candy@date.com"},
+		Application{AppliedDate: 200, Github: "candy", Email: "candy@date.com"},
+		Application{AppliedDate: 300, Github: "candy", Email: "candy@example.com"},
+		Application{AppliedDate: 400, Github: "candy", Email: "candy@example.com"},
+		Application{AppliedDate: 500, Github: "dandy", Email: "dandy@date.com"},
+	)
+	store := NewDynamoDBStore(table, "applications", "github-index")
+
+	latest, err := store.GetApplication(context.Background(), "candy")
+	if err != nil || latest.AppliedDate != 400 {
+		t.Fatalf("expected the latest application, got %+v %v", latest, err)
+	}
+
+	// The last page is full, so DynamoDB cannot tell there is nothing after
+	// it and the next page comes back empty
+	var dates []int64
+	cursor := ""
+	for pages := 0; ; pages++ {
+		if pages > 3 {
+			t.Fatalf("paging did not stop, at cursor %q", cursor)
+		}
+		page, next, err := store.ListUserApplications(context.Background(), "candy", cursor, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, app := range page {
+			dates = append(dates, app.AppliedDate)
+		}
+		if next == "" {
+			if len(page) != 0 {
+				t.Fatalf("expected the page after the last full page to be empty, got %+v", page)
+			}
+			break
+		}
+		cursor = next
+	}
+	if len(dates) != 4 || dates[0] != 400 || dates[3] != 100 {
+		t.Fatalf("expected every application newest first, got %v", dates)
+	}
+
+	// A short last page ends the listing
+	page, next, err := store.ListUserApplications(context.Background(), "candy", "", 10)
+	if err != nil || len(page) != 4 || next != "" {
+		t.Fatalf("expected a single short page, got %d applications and cursor %q, %v", len(page), next, err)
+	}
+
+	page, next, err = store.ListUserApplications(context.Background(), "nobody", "", 2)
+	if err != nil || len(page) != 0 || next != "" {
+		t.Fatalf("expected no applications, got %+v %q %v", page, next, err)
+	}
+	if _, err := store.GetApplication(context.Background(), "nobody"); !errors.As(err, new(*emptyResultError)) {
+		t.Fatalf("expected an empty result, got %v", err)
+	}
+
+	if _, _, err := store.ListUserApplications(context.Background(), "candy", "not-a-cursor", 2); err == nil {
+		t.Fatalf("expected an invalid cursor to be refused")
+	}
+}
+
+func TestDynamoDBCheckIndex(t *testing.T) {
+	table := newFakeApplicationTable()
+	store := NewDynamoDBStore(table, "applications", "github-index")
+	if err := store.CheckIndex(context.Background()); err == nil {
+		t.Fatalf("expected a missing index to be refused")
+	}
+
+	table.indexKeys = []*dynamodb.KeySchemaElement{
+		{AttributeName: aws.String("github"), KeyType: aws.String(dynamodb.KeyTypeHash)},
+	}
+	if err := store.CheckIndex(context.Background()); err == nil {
+		t.Fatalf("expected an index without a sort key to be refused")
+	}
+
+	table.indexKeys = append(table.indexKeys, &dynamodb.KeySchemaElement{AttributeName: aws.String("applied_date"), KeyType: aws.String(dynamodb.KeyTypeRange)})
+	if err := store.CheckIndex(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDynamoDBUpdateOfMissingApplication(t *testing.T) {
+	table := newFakeApplicationTable(Application{AppliedDate: 100, Github: "candy", Email: "candy@date.com", Version: 2})
+	store := NewDynamoDBStore(table, "applications", "github-index")
+
+	missing := Application{AppliedDate: 200, Github: "dandy", Email: "dandy@date.com", Name: "Dandy Date"}
+	if err := store.UpdateApplication(context.Background(), missing); !errors.As(err, new(*ApplicationNotFoundError)) {
+		t.Fatalf("expected an update of a missing application to be refused, got %v", err)
+	}
+	if len(table.items) != 1 {
+		t.Fatalf("an update should not create an application: %+v", table.items)
+	}
+
+	stale := Application{AppliedDate: 100, Github: "candy", Email: "candy@date.com", Name: "Candy Dated", Version: 1}
+	if err := store.UpdateApplication(context.Background(), stale); !errors.As(err, new(*VersionConflictError)) {
+		t.Fatalf("expected a version conflict for a stale update, got %v", err)
+	}
+	stale.Version = 2
+	if err := store.UpdateApplication(context.Background(), stale); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDynamoDBRoleCounts(t *testing.T) {
+	table := newFakeApplicationTable()
+	store := NewDynamoDBStore(table, "applications", "github-index")
+	swe := Role{ID: "swe", Title: "Software Engineer", MaxApplications: 1}
+	sre := Role{ID: "sre", Title: "Site Reliability Engineer"}
+
+	if err := store.CountRoleApplications(context.Background(), []Role{sre, swe}); err != nil {
+		t.Fatal(err)
+	}
+	// The transaction names the full role, and counts none of the roles
+	var refused *RoleClosedError
+	if err := store.CountRoleApplications(context.Background(), []Role{sre, swe}); !errors.As(err, &refused) || refused.Role.ID != "swe" || refused.Reason != reasonFull {
+		t.Fatalf("expected the full role to be refused, got %v", err)
+	}
+	counts, err := store.RoleApplicationCounts(context.Background(), []string{"swe", "sre", "pm"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if counts["swe"] != 1 || counts["sre"] != 1 || counts["pm"] != 0 {
+		t.Fatalf("unexpected counts %v", counts)
+	}
+
+	if err := store.UncountRoleApplications(context.Background(), []string{"swe"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.CountRoleApplications(context.Background(), []Role{swe}); err != nil {
+		t.Fatalf("expected the role to have room once uncounted: %v", err)
+	}
+}
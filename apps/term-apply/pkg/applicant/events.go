@@ -0,0 +1,220 @@
+package applicant
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+)
+
+/*
+
+Every change to an application is recorded as an immutable Event in an
+EventStore kept apart from the applications themselves, so the history
+survives email changes and deletes. Events are only ever appended.
+
+Each event records who made the change and, for edits, the before and after
+value of every field that changed as JSON.
+
+*/
+
+type EventType string
+
+const (
+	EventCreated        EventType = "created"
+	EventUpdated        EventType = "updated"
+	EventRecreated      EventType = "recreated"
+	EventStateChanged   EventType = "state_changed"
+	EventResumeUploaded EventType = "resume_uploaded"
+)
+
+type Event struct {
+	Github      string        `json:"github" dynamodbav:"github"` // the applicant
+	ID          string        `json:"id" dynamodbav:"id"`         // unique, sorts in the order events happened
+	Time        int64         `json:"time" dynamodbav:"time"`     // unix time
+	Type        EventType     `json:"type" dynamodbav:"type"`
+	Actor       string        `json:"actor" dynamodbav:"actor"` // github user who made the change
+	Email       string        `json:"email,omitempty" dynamodbav:"email,omitempty"`
+	AppliedDate int64         `json:"applied_date,omitempty" dynamodbav:"applied_date,omitempty"`
+	Changes     []FieldChange `json:"changes,omitempty" dynamodbav:"changes,omitempty"`
+}
+
+// FieldChange is the JSON encoded value of a field before and after a change.
+// Before is empty for new fields and After for removed ones.
+type FieldChange struct {
+	Field  string `json:"field" dynamodbav:"field"`
+	Before string `json:"before,omitempty" dynamodbav:"before,omitempty"`
+	After  string `json:"after,omitempty" dynamodbav:"after,omitempty"`
+}
+
+// EventStore keeps the audit trail of applications
+type EventStore interface {
+	// Records event, which must never overwrite an existing event
+	AppendEvent(ctx context.Context, event Event) error
+	// Returns every event for the provided user, oldest first
+	ListEvents(ctx context.Context, github string) ([]Event, error)
+}
+
+func newEvent(eventType EventType, actor string, before, after Application, now time.Time) Event {
+	return Event{
+		Github:      after.Github,
+		ID:          newEventID(now),
+		Time:        now.Unix(),
+		Type:        eventType,
+		Actor:       actor,
+		Email:       after.Email,
+		AppliedDate: after.AppliedDate,
+		Changes:     diffApplications(before, after),
+	}
+}
+
+// newEventID orders events by time, with a random suffix so events in the
+// same nanosecond never collide
+func newEventID(now time.Time) string {
+	b := make([]byte, 4)
+	if _, err := rand.Read(b); err != nil {
+		panic("Failed to generate event id")
+	}
+	return fmt.Sprintf("%020d-%s", now.UnixNano(), hex.EncodeToString(b))
+}
+
+// diffApplications returns the fields that differ between before and after,
+// ignoring the version
+func diffApplications(before, after Application) []FieldChange {
+	beforeFields, afterFields := applicationFields(before), applicationFields(after)
+
+	names := make([]string, 0, len(afterFields))
+	for name := range beforeFields {
+		names = append(names, name)
+	}
+	for name := range afterFields {
+		if _, ok := beforeFields[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var changes []FieldChange
+	for _, name := range names {
+		if name == "version" || string(beforeFields[name]) == string(afterFields[name]) {
+			continue
+		}
+		changes = append(changes, FieldChange{
+			Field:  name,
+			Before: string(beforeFields[name]),
+			After:  string(afterFields[name]),
+		})
+	}
+	return changes
+}
+
+func applicationFields(app Application) map[string]json.RawMessage {
+	fields := map[string]json.RawMessage{}
+	if app.Github == "" {
+		return fields
+	}
+	data, err := json.Marshal(app)
+	if err != nil {
+		log.Printf("Failed to encode application for %s: %v", app.Github, err)
+		return fields
+	}
+	json.Unmarshal(data, &fields)
+	return fields
+}
+
+// recordEvent appends event to the audit trail. The change it describes has
+// already been made, so a failure is logged rather than returned.
+func (a *ApplicantManager) recordEvent(event Event) {
+	ctx, cancel := withTimeout(context.Background(), a.config.WriteTimeout)
+	defer cancel()
+	if err := a.events.AppendEvent(ctx, event); err != nil {
+		log.Printf("Failed to record %s event for %s: %v", event.Type, event.Github, err)
+	}
+}
+
+// recordWrite records a candidate's write of app, where before is the
+// application it replaced, if any
+func (a *ApplicantManager) recordWrite(state writeState, before, app Application) {
+	// Stores keep every field the candidate cannot change
+	after := before
+	after.setCandidateFields(app)
+	after.Version = before.Version + 1
+
+	eventType := EventUpdated
+	switch state {
+	case newApp:
+		before, after = Application{}, app
+		after.Version = 1
+		eventType = EventCreated
+	case recreateApp:
+		eventType = EventRecreated
+	}
+	a.recordEvent(newEvent(eventType, app.Github, before, after, time.Now()))
+}
+
+// RecordResumeUpload adds a resume upload by github to their history
+func (a *ApplicantManager) RecordResumeUpload(github string) {
+	a.recordEvent(Event{
+		Github: github,
+		ID:     newEventID(time.Now()),
+		Time:   time.Now().Unix(),
+		Type:   EventResumeUploaded,
+		Actor:  github,
+	})
+}
+
+// History returns every recorded event for github, oldest first
+func (a *ApplicantManager) History(ctx context.Context, github string) ([]Event, error) {
+	ctx, cancel := withTimeout(ctx, a.config.ReadTimeout)
+	defer cancel()
+	return a.events.ListEvents(ctx, github)
+}
+
+// MemoryEventStore is an in-process EventStore for tests and local
+// development
+type MemoryEventStore struct {
+	lock   sync.RWMutex
+	events map[string][]Event
+}
+
+func NewMemoryEventStore() *MemoryEventStore {
+	return &MemoryEventStore{events: map[string][]Event{}}
+}
+
+func (m *MemoryEventStore) AppendEvent(ctx context.Context, event Event) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	for _, existing := range m.events[event.Github] {
+		if existing.ID == event.ID {
+			return fmt.Errorf("event %s already exists for %s", event.ID, event.Github)
+		}
+	}
+	m.events[event.Github] = append(m.events[event.Github], event)
+	return nil
+}
+
+func (m *MemoryEventStore) ListEvents(ctx context.Context, github string) ([]Event, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	events := make([]Event, len(m.events[github]))
+	copy(events, m.events[github])
+	sort.Slice(events, func(i, j int) bool {
+		return events[i].ID < events[j].ID
+	})
+	return events, nil
+}
@@ -0,0 +1,84 @@
+package applicant
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var eventsBucket = []byte("events")
+
+// BoltEventStore keeps the audit trail in its own bbolt database, with one
+// nested bucket per github user keyed by event ID.
+type BoltEventStore struct {
+	db *bolt.DB
+}
+
+func NewBoltEventStore(path string) (*BoltEventStore, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("cannot open bolt database %s: %w", path, err)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(eventsBucket)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &BoltEventStore{db: db}, nil
+}
+
+func (b *BoltEventStore) Close() error {
+	return b.db.Close()
+}
+
+func (b *BoltEventStore) AppendEvent(ctx context.Context, event Event) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	return b.db.Update(func(tx *bolt.Tx) error {
+		events, err := tx.Bucket(eventsBucket).CreateBucketIfNotExists([]byte(event.Github))
+		if err != nil {
+			return err
+		}
+		if events.Get([]byte(event.ID)) != nil {
+			return fmt.Errorf("event %s already exists for %s", event.ID, event.Github)
+		}
+		return events.Put([]byte(event.ID), data)
+	})
+}
+
+func (b *BoltEventStore) ListEvents(ctx context.Context, github string) ([]Event, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var events []Event
+	err := b.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(eventsBucket).Bucket([]byte(github))
+		if bucket == nil {
+			return nil
+		}
+		return bucket.ForEach(func(_, data []byte) error {
+			var event Event
+			if err := json.Unmarshal(data, &event); err != nil {
+				return err
+			}
+			events = append(events, event)
+			return nil
+		})
+	})
+	return events, err
+}
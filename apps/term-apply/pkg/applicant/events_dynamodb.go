@@ -0,0 +1,77 @@
+package applicant
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+)
+
+/*
+
+The expected schema for the DynamoDB events table is as follows:
+
+> github: candydate100 - string - Primary/Partition DDB Key <br>
+> id: zero padded unix nanoseconds + "-" + random suffix - string - Sort DDB Key <br>
+> time: unix time - number <br>
+> type: created, updated, recreated, state_changed or resume_uploaded - string <br>
+> actor: github user who made the change - string <br>
+> email: string <br>
+> applied_date: number <br>
+> changes: List of {field: string, before: JSON string, after: JSON string} <br>
+
+*/
+
+// DynamoDBEventStore is an EventStore backed by its own DynamoDB table
+type DynamoDBEventStore struct {
+	svc   dynamodbiface.DynamoDBAPI
+	table string
+}
+
+func NewDynamoDBEventStore(svc dynamodbiface.DynamoDBAPI, table string) *DynamoDBEventStore {
+	return &DynamoDBEventStore{
+		svc:   svc,
+		table: table,
+	}
+}
+
+func (d *DynamoDBEventStore) AppendEvent(ctx context.Context, event Event) error {
+	item, err := dynamodbattribute.MarshalMap(event)
+	if err != nil {
+		return err
+	}
+
+	_, err = d.svc.PutItemWithContext(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(d.table),
+		Item:      item,
+		// Events are immutable
+		ConditionExpression: aws.String("attribute_not_exists(id)"),
+	})
+	return err
+}
+
+func (d *DynamoDBEventStore) ListEvents(ctx context.Context, github string) ([]Event, error) {
+	var events []Event
+	var decodeErr error
+	err := d.svc.QueryPagesWithContext(ctx, &dynamodb.QueryInput{
+		TableName:              aws.String(d.table),
+		KeyConditionExpression: aws.String("github = :github"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":github": {S: aws.String(github)},
+		},
+		ScanIndexForward: aws.Bool(true),
+	}, func(page *dynamodb.QueryOutput, lastPage bool) bool {
+		var items []Event
+		if decodeErr = dynamodbattribute.UnmarshalListOfMaps(page.Items, &items); decodeErr != nil {
+			return false
+		}
+		events = append(events, items...)
+		return true
+	})
+	if err == nil {
+		err = decodeErr
+	}
+	return events, err
+}
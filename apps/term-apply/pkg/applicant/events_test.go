@@ -0,0 +1,147 @@
+package applicant
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func newTestBoltEventStore(t *testing.T) *BoltEventStore {
+	t.Helper()
+	store, err := NewBoltEventStore(filepath.Join(t.TempDir(), "events.db"))
+	if err != nil {
+		t.Fatalf("failed to open bolt event store: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+func eventTypes(events []Event) []EventType {
+	types := make([]EventType, 0, len(events))
+	for _, event := range events {
+		types = append(types, event.Type)
+	}
+	return types
+}
+
+func TestDiffApplications(t *testing.T) {
+	before := Application{AppliedDate: 100, Github: "candy", Email: "candy@date.com", Name: "Candy Date", Version: 1}
+	after := before
+	after.Email = "candy@example.com"
+	after.ResumeReview = true
+	after.Version = 2
+
+	want := []FieldChange{
+		{Field: "email", Before: `"candy@date.com"`, After: `"candy@example.com"`},
+		{Field: "resume_review", After: "true"},
+	}
+	if changes := diffApplications(before, after); !reflect.DeepEqual(changes, want) {
+		t.Fatalf("unexpected changes:\n got %+v\nwant %+v", changes, want)
+	}
+	if changes := diffApplications(before, before); len(changes) != 0 {
+		t.Fatalf("expected no changes, got %+v", changes)
+	}
+}
+
+func TestEventStoresAppendOnly(t *testing.T) {
+	for name, store := range map[string]EventStore{"memory": NewMemoryEventStore(), "bolt": newTestBoltEventStore(t)} {
+		now := time.Unix(100, 0)
+		first := Event{Github: "candy", ID: newEventID(now), Type: EventCreated, Actor: "candy"}
+		second := Event{Github: "candy", ID: newEventID(now.Add(time.Second)), Type: EventUpdated, Actor: "staff"}
+		for _, event := range []Event{second, first, {Github: "other", ID: first.ID, Type: EventCreated}} {
+			if err := store.AppendEvent(context.Background(), event); err != nil {
+				t.Fatalf("%s: %v", name, err)
+			}
+		}
+
+		overwrite := first
+		overwrite.Type = EventRecreated
+		if err := store.AppendEvent(context.Background(), overwrite); err == nil {
+			t.Fatalf("%s: expected an existing event not to be overwritten", name)
+		}
+
+		events, err := store.ListEvents(context.Background(), "candy")
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if !reflect.DeepEqual(events, []Event{first, second}) {
+			t.Fatalf("%s: unexpected events %+v", name, events)
+		}
+	}
+}
+
+func TestManagerRecordsCandidateWrites(t *testing.T) {
+	store := NewMemoryStore()
+	am := newTestManager(t, store)
+
+	addAndWait(t, am, "candy", "Candy Date", "candy@date.com", "software-engineer")
+	addAndWait(t, am, "candy", "Candy Date", "candy@date.com", "senior-software-engineer")
+	addAndWait(t, am, "candy", "Candy Date", "candy@example.com", "senior-software-engineer")
+
+	events, err := am.History(context.Background(), "candy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []EventType{EventCreated, EventUpdated, EventRecreated}
+	if types := eventTypes(events); !reflect.DeepEqual(types, want) {
+		t.Fatalf("unexpected events %v", types)
+	}
+	for _, event := range events {
+		if event.Actor != "candy" {
+			t.Fatalf("candidate writes should be made by the candidate: %+v", event)
+		}
+	}
+
+	recreated := events[2]
+	if recreated.Email != "candy@example.com" || len(recreated.Changes) != 1 {
+		t.Fatalf("unexpected recreate event %+v", recreated)
+	}
+	change := recreated.Changes[0]
+	if change.Field != "email" || change.Before != `"candy@date.com"` || change.After != `"candy@example.com"` {
+		t.Fatalf("unexpected email change %+v", change)
+	}
+}
+
+func TestManagerRecordsStaffChanges(t *testing.T) {
+	store := NewMemoryStore()
+	am := newTestManager(t, store)
+	addAndWait(t, am, "candy", "Candy Date", "candy@date.com", "software-engineer")
+	app, _ := store.GetApplication(context.Background(), "candy")
+
+	app, err := am.TransitionApplication(context.Background(), "staff", app, StateRejected)
+	if err != nil {
+		t.Fatal(err)
+	}
+	am.RecordResumeUpload("candy")
+
+	events, _ := am.History(context.Background(), "candy")
+	want := []EventType{EventCreated, EventStateChanged, EventResumeUploaded}
+	if types := eventTypes(events); !reflect.DeepEqual(types, want) {
+		t.Fatalf("unexpected events %v", types)
+	}
+	if events[1].Actor != "staff" {
+		t.Fatalf("state change should be made by staff: %+v", events[1])
+	}
+	changed := map[string]bool{}
+	for _, change := range events[1].Changes {
+		changed[change.Field] = true
+	}
+	if !changed["state"] || !changed["rejected"] {
+		t.Fatalf("state change should record the new state: %+v", events[1].Changes)
+	}
+}
+
+func TestRetriedWriteIsRecorded(t *testing.T) {
+	store := &flakyStore{MemoryStore: NewMemoryStore(), failures: 1}
+	am, _ := newJournaledManager(t, store, 5)
+
+	addQueued(t, am, "candy", "Candy Date", "candy@date.com", "senior-software-engineer")
+	waitFor(t, func() bool { return am.journal.pending() == 0 })
+
+	events, _ := am.History(context.Background(), "candy")
+	if types := eventTypes(events); !reflect.DeepEqual(types, []EventType{EventCreated}) {
+		t.Fatalf("expected the retried write to be recorded, got %v", types)
+	}
+}
@@ -0,0 +1,115 @@
+package applicant
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	minRating = 1
+	maxRating = 5
+)
+
+// InterviewRound pairs an interview with its round number
+type InterviewRound struct {
+	Round string
+	Interview
+}
+
+// ListInterviews returns the interview rounds of github's latest
+// application in round order
+func (a *ApplicantManager) ListInterviews(ctx context.Context, github string) ([]InterviewRound, error) {
+	ctx, cancel := withTimeout(ctx, a.config.ReadTimeout)
+	defer cancel()
+
+	app, err := a.store.GetApplication(ctx, github)
+	if err != nil {
+		return nil, err
+	}
+
+	rounds := make([]InterviewRound, 0, len(app.Interviews))
+	for _, round := range app.InterviewRounds() {
+		rounds = append(rounds, InterviewRound{Round: round, Interview: app.Interviews[round]})
+	}
+	return rounds, nil
+}
+
+// AddInterview records interview as the next round of app, returning the
+// saved application and the new round
+func (a *ApplicantManager) AddInterview(ctx context.Context, actor string, app Application, interview Interview) (Application, string, error) {
+	if err := checkInterview(interview); err != nil {
+		return app, "", err
+	}
+
+	round := nextInterviewRound(app.Interviews)
+	saved, err := a.staffEdit(ctx, actor, app, func(app *Application) error {
+		if app.CanTransition(StateInterviewing) {
+			if err := app.transition(StateInterviewing, time.Now()); err != nil {
+				return err
+			}
+		}
+		app.Interviews = copyInterviews(app.Interviews)
+		app.Interviews[round] = interview
+		return nil
+	})
+	return saved, round, err
+}
+
+// UpdateInterview replaces the scorecard of an existing round of app
+func (a *ApplicantManager) UpdateInterview(ctx context.Context, actor string, app Application, round string, interview Interview) (Application, error) {
+	if _, exists := app.Interviews[round]; !exists {
+		return app, fmt.Errorf("application for %s has no interview round %s", app.Github, round)
+	}
+	if err := checkInterview(interview); err != nil {
+		return app, err
+	}
+
+	return a.staffEdit(ctx, actor, app, func(app *Application) error {
+		app.Interviews = copyInterviews(app.Interviews)
+		app.Interviews[round] = interview
+		return nil
+	})
+}
+
+func checkInterview(interview Interview) error {
+	var errs []string
+	if interview.Date <= 0 {
+		errs = append(errs, "date")
+	}
+	if interview.Rating < minRating || interview.Rating > maxRating {
+		errs = append(errs, "rating")
+	}
+	for competency, rating := range interview.Competencies {
+		if strings.TrimSpace(competency) == "" || rating < minRating || rating > maxRating {
+			errs = append(errs, fmt.Sprintf("competency %q", competency))
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%d invalid interview fields %v", len(errs), strings.Join(errs, ","))
+	}
+	return nil
+}
+
+// nextInterviewRound numbers rounds from 1, after the highest existing round
+func nextInterviewRound(interviews map[string]Interview) string {
+	next := 1
+	for round := range interviews {
+		if n, err := strconv.Atoi(round); err == nil && n >= next {
+			next = n + 1
+		}
+	}
+	return strconv.Itoa(next)
+}
+
+// copyInterviews copies interviews so edits never change an application
+// that was handed out
+func copyInterviews(interviews map[string]Interview) map[string]Interview {
+	copied := make(map[string]Interview, len(interviews)+1)
+	for round, interview := range interviews {
+		copied[round] = interview
+	}
+	return copied
+}
@@ -0,0 +1,91 @@
+package applicant
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func scorecard(rating int) Interview {
+	return Interview{
+		Date:         100,
+		Pass:         rating >= 3,
+		Notes:        []byte("walked through a production outage"),
+		Interviewer:  "staff",
+		Rating:       rating,
+		Competencies: map[string]int{"systems design": rating, "communication": 4},
+	}
+}
+
+func TestAddAndUpdateInterviews(t *testing.T) {
+	for name, store := range map[string]ApplicationStore{"memory": NewMemoryStore(), "bolt": newTestBoltStore(t)} {
+		am := newTestManager(t, store)
+		addAndWait(t, am, "candy", "Candy Date", "candy@date.com", "software-engineer")
+		app, _ := store.GetApplication(context.Background(), "candy")
+
+		app, round, err := am.AddInterview(context.Background(), "staff", app, scorecard(4))
+		if err != nil || round != "1" {
+			t.Fatalf("%s: unexpected first round %q: %v", name, round, err)
+		}
+		app, round, err = am.AddInterview(context.Background(), "staff", app, scorecard(2))
+		if err != nil || round != "2" {
+			t.Fatalf("%s: unexpected second round %q: %v", name, round, err)
+		}
+		if _, err := am.UpdateInterview(context.Background(), "staff", app, "1", scorecard(5)); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+
+		rounds, err := am.ListInterviews(context.Background(), "candy")
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		want := []InterviewRound{{Round: "1", Interview: scorecard(5)}, {Round: "2", Interview: scorecard(2)}}
+		if !reflect.DeepEqual(rounds, want) {
+			t.Fatalf("%s: unexpected interviews:\n got %+v\nwant %+v", name, rounds, want)
+		}
+	}
+}
+
+func TestUpdateInterviewDoesNotChangeCaller(t *testing.T) {
+	store := NewMemoryStore()
+	am := newTestManager(t, store)
+	addAndWait(t, am, "candy", "Candy Date", "candy@date.com", "software-engineer")
+	app, _ := store.GetApplication(context.Background(), "candy")
+	app, _, _ = am.AddInterview(context.Background(), "staff", app, scorecard(4))
+
+	if _, err := am.UpdateInterview(context.Background(), "staff", app, "1", scorecard(1)); err != nil {
+		t.Fatal(err)
+	}
+	if app.Interviews["1"].Rating != 4 {
+		t.Fatalf("the application passed in should not be modified")
+	}
+
+	// app is now stale
+	if _, err := am.UpdateInterview(context.Background(), "staff", app, "1", scorecard(3)); !errors.As(err, new(*VersionConflictError)) {
+		t.Fatalf("expected a version conflict, got %v", err)
+	}
+}
+
+func TestInvalidInterviews(t *testing.T) {
+	store := NewMemoryStore()
+	am := newTestManager(t, store)
+	addAndWait(t, am, "candy", "Candy Date", "candy@date.com", "software-engineer")
+	app, _ := store.GetApplication(context.Background(), "candy")
+
+	invalid := map[string]Interview{
+		"no date":           {Rating: 3},
+		"rating too high":   {Date: 100, Rating: 6},
+		"unrated":           {Date: 100},
+		"competency rating": {Date: 100, Rating: 3, Competencies: map[string]int{"testing": 0}},
+		"unnamed":           {Date: 100, Rating: 3, Competencies: map[string]int{" ": 3}},
+	}
+	for name, interview := range invalid {
+		if _, _, err := am.AddInterview(context.Background(), "staff", app, interview); err == nil {
+			t.Errorf("%s: expected interview to be rejected", name)
+		}
+	}
+	if _, err := am.UpdateInterview(context.Background(), "staff", app, "7", scorecard(3)); err == nil {
+		t.Errorf("expected missing round to be rejected")
+	}
+}
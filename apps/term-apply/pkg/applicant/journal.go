@@ -0,0 +1,404 @@
+package applicant
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+/*
+
+writeJournal keeps application writes that failed so they can be retried.
+
+Pending entries are kept in a JSON lines file that is rewritten atomically on
+every change, so they survive a restart. Entries that still fail after the
+configured number of attempts are appended to a dead-letter file for manual
+recovery instead of being dropped.
+
+A write made while the applicant has pending entries was built from the
+stored application, which the pending entries have not reached yet. It is
+folded into the applicant's last entry when both write the same application,
+so replaying it does not conflict with that entry or create a second
+application.
+
+*/
+
+const maxRetryBackoff = 10 * time.Minute
+
+type journalEntry struct {
+	Seq         uint64       `json:"seq"`
+	Record      Application  `json:"record"`
+	Before      *Application `json:"before,omitempty"` // the application the write edits, for the audit trail
+	PrevEmail   string       `json:"prev_email,omitempty"`
+	WriteState  writeState   `json:"write_state"`
+	Attempts    int          `json:"attempts"`
+	NextAttempt time.Time    `json:"next_attempt"`
+	LastError   string       `json:"last_error,omitempty"`
+	Counted     []string     `json:"counted,omitempty"` // IDs of the roles counted for the write
+}
+
+type writeJournal struct {
+	lock           sync.Mutex
+	path           string // pending entries, not persisted when empty
+	deadLetterPath string // entries that exhausted their attempts
+	maxAttempts    int
+	backoff        time.Duration
+	entries        []journalEntry
+	nextSeq        uint64
+}
+
+func newWriteJournal(path, deadLetterPath string, maxAttempts int, backoff time.Duration) (*writeJournal, error) {
+	j := &writeJournal{
+		path:           path,
+		deadLetterPath: deadLetterPath,
+		maxAttempts:    maxAttempts,
+		backoff:        backoff,
+		nextSeq:        1,
+	}
+	if path == "" {
+		return j, nil
+	}
+
+	entries, err := readJournalFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot load write journal %s: %w", path, err)
+	}
+	for _, entry := range entries {
+		if entry.Seq >= j.nextSeq {
+			j.nextSeq = entry.Seq + 1
+		}
+	}
+	j.entries = entries
+	if len(entries) > 0 {
+		log.Printf("Loaded %d pending application writes from %s", len(entries), path)
+	}
+	return j, nil
+}
+
+// add records a write that could not be completed. A nil err queues the
+// write without counting a failed attempt.
+func (j *writeJournal) add(packet applicationPacket, err error) {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+
+	j.append(packet, err)
+	j.persist()
+}
+
+// queue records a write for an applicant with pending entries, folding it
+// into their last entry where possible. It returns the IDs of roles the
+// write counted that the entry had already counted.
+func (j *writeJournal) queue(packet applicationPacket) []string {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+
+	last := -1
+	for i, entry := range j.entries {
+		if entry.Record.Github == packet.app.Github {
+			last = i
+		}
+	}
+	var recounted []string
+	if last == -1 || !j.entries[last].fold(packet) {
+		j.append(packet, nil)
+	} else {
+		recounted = j.entries[last].addCounted(packet.counted)
+	}
+	j.persist()
+	return recounted
+}
+
+// append adds an entry for packet. Callers must hold the lock and persist
+// the journal.
+func (j *writeJournal) append(packet applicationPacket, err error) {
+	entry := journalEntry{
+		Seq:         j.nextSeq,
+		Record:      packet.app,
+		PrevEmail:   packet.prevEmail,
+		WriteState:  packet.writeState,
+		NextAttempt: time.Now(),
+		Counted:     packet.counted,
+	}
+	if packet.before.Github != "" {
+		before := packet.before
+		entry.Before = &before
+	}
+	if err != nil {
+		entry.Attempts = 1
+		entry.LastError = err.Error()
+		entry.NextAttempt = time.Now().Add(j.delay(1))
+	}
+	j.nextSeq++
+	j.entries = append(j.entries, entry)
+}
+
+// addCounted adds the roles a folded write counted to those of entry, and
+// returns the IDs entry had already counted
+func (entry *journalEntry) addCounted(roleIDs []string) []string {
+	counted := map[string]bool{}
+	for _, id := range entry.Counted {
+		counted[id] = true
+	}
+	var recounted []string
+	for _, id := range roleIDs {
+		if counted[id] {
+			recounted = append(recounted, id)
+		} else {
+			counted[id] = true
+			entry.Counted = append(entry.Counted, id)
+		}
+	}
+	return recounted
+}
+
+// fold replaces the write of entry with the later write in packet, and
+// reports whether it could. A pending create takes the candidate's latest
+// fields. A pending edit takes the later edit of the same application,
+// recreating the application if the later edit changed the stored email.
+func (entry *journalEntry) fold(packet applicationPacket) bool {
+	app := packet.app
+	switch {
+	case entry.WriteState == newApp && packet.writeState == newApp:
+		app.AppliedDate = entry.Record.AppliedDate
+		entry.Record = app
+		return true
+	case entry.WriteState == newApp || packet.writeState == newApp:
+		return false
+	}
+
+	stored := entry.Record.Email
+	if entry.WriteState == recreateApp {
+		stored = entry.PrevEmail
+	}
+	edited := app.Email
+	if packet.writeState == recreateApp {
+		edited = packet.prevEmail
+	}
+	if edited != stored || app.AppliedDate != entry.Record.AppliedDate {
+		return false
+	}
+
+	app.Version = entry.Record.Version
+	entry.Record = app
+	entry.WriteState, entry.PrevEmail = updateApp, ""
+	if app.Email != stored {
+		entry.WriteState, entry.PrevEmail = recreateApp, stored
+	}
+	return true
+}
+
+// hasPending reports whether github has writes waiting to be retried
+func (j *writeJournal) hasPending(github string) bool {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+
+	for _, entry := range j.entries {
+		if entry.Record.Github == github {
+			return true
+		}
+	}
+	return false
+}
+
+// due returns the entries that should be retried now. Only the oldest entry
+// for each applicant is returned so writes are replayed in order.
+func (j *writeJournal) due(now time.Time) []journalEntry {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+
+	var due []journalEntry
+	seen := map[string]bool{}
+	for _, entry := range j.entries {
+		if seen[entry.Record.Github] {
+			continue
+		}
+		seen[entry.Record.Github] = true
+		if !entry.NextAttempt.After(now) {
+			due = append(due, entry)
+		}
+	}
+	return due
+}
+
+// entry returns the pending entry with seq
+func (j *writeJournal) entry(seq uint64) (journalEntry, bool) {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+
+	if i := j.find(seq); i != -1 {
+		return j.entries[i], true
+	}
+	return journalEntry{}, false
+}
+
+// succeeded removes a retried entry
+func (j *writeJournal) succeeded(seq uint64) {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+
+	if i := j.find(seq); i != -1 {
+		j.entries = append(j.entries[:i], j.entries[i+1:]...)
+		j.persist()
+	}
+}
+
+// failed records another failed attempt, moving the entry to the dead-letter
+// file once it has used all of its attempts, and reports whether it did.
+// Version conflicts and missing applications are dead-lettered immediately,
+// since the write is based on stale data.
+func (j *writeJournal) failed(seq uint64, err error) bool {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+
+	i := j.find(seq)
+	if i == -1 {
+		return false
+	}
+
+	entry := &j.entries[i]
+	entry.Attempts++
+	entry.LastError = err.Error()
+	if entry.Attempts < j.maxAttempts && !isStaleWrite(err) {
+		entry.NextAttempt = time.Now().Add(j.delay(entry.Attempts))
+		j.persist()
+		return false
+	}
+
+	log.Printf(
+		"Giving up on application write for %s after %d attempts, moving to %s: %v",
+		entry.Record.Github,
+		entry.Attempts,
+		j.deadLetterPath,
+		err,
+	)
+	if err := j.deadLetter(*entry); err != nil {
+		// Keep the entry pending rather than lose it
+		log.Printf("Failed to write dead-letter entry for %s: %v", entry.Record.Github, err)
+		entry.NextAttempt = time.Now().Add(maxRetryBackoff)
+		j.persist()
+		return false
+	}
+	j.entries = append(j.entries[:i], j.entries[i+1:]...)
+	j.persist()
+	return true
+}
+
+func (j *writeJournal) pending() int {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+	return len(j.entries)
+}
+
+// delay is the exponential backoff before the next attempt. Callers must
+// hold the lock.
+func (j *writeJournal) delay(attempts int) time.Duration {
+	delay := j.backoff
+	for i := 1; i < attempts && delay < maxRetryBackoff; i++ {
+		delay *= 2
+	}
+	if delay > maxRetryBackoff {
+		delay = maxRetryBackoff
+	}
+	return delay
+}
+
+// find returns the index of the entry with seq, or -1. Callers must hold the
+// lock.
+func (j *writeJournal) find(seq uint64) int {
+	for i, entry := range j.entries {
+		if entry.Seq == seq {
+			return i
+		}
+	}
+	return -1
+}
+
+// persist atomically rewrites the journal file. Callers must hold the lock.
+func (j *writeJournal) persist() {
+	if j.path == "" {
+		return
+	}
+	if err := writeJournalFile(j.path, j.entries); err != nil {
+		log.Printf("Failed to persist write journal %s: %v", j.path, err)
+	}
+}
+
+func (j *writeJournal) deadLetter(entry journalEntry) error {
+	if j.deadLetterPath == "" {
+		return fmt.Errorf("no dead-letter file configured")
+	}
+	f, err := os.OpenFile(j.deadLetterPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		return err
+	}
+	return f.Sync()
+}
+
+func readJournalFile(path string) ([]journalEntry, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	// Entries are decoded as a stream, since a line holding long answers
+	// and the previous record can outgrow a scanner's buffer
+	var entries []journalEntry
+	decoder := json.NewDecoder(bufio.NewReader(f))
+	for {
+		var entry journalEntry
+		if err := decoder.Decode(&entry); err == io.EOF {
+			return entries, nil
+		} else if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+}
+
+func writeJournalFile(path string, entries []journalEntry) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".journal-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	w := bufio.NewWriter(tmp)
+	for _, entry := range entries {
+		data, err := json.Marshal(entry)
+		if err != nil {
+			tmp.Close()
+			return err
+		}
+		w.Write(append(data, '\n'))
+	}
+	if err := w.Flush(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,265 @@
+package applicant
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// flakyStore fails the first failures writes before delegating to a
+// MemoryStore. A negative failures fails every write.
+type flakyStore struct {
+	*MemoryStore
+	lock     sync.Mutex
+	failures int
+}
+
+func (f *flakyStore) fail() error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if f.failures != 0 {
+		f.failures--
+		return errors.New("service unavailable")
+	}
+	return nil
+}
+
+func (f *flakyStore) PutApplication(ctx context.Context, app Application) error {
+	if err := f.fail(); err != nil {
+		return err
+	}
+	return f.MemoryStore.PutApplication(ctx, app)
+}
+
+func (f *flakyStore) UpdateApplication(ctx context.Context, app Application) error {
+	if err := f.fail(); err != nil {
+		return err
+	}
+	return f.MemoryStore.UpdateApplication(ctx, app)
+}
+
+func newJournaledManager(t *testing.T, store ApplicationStore, maxAttempts int) (*ApplicantManager, ManagerConfig) {
+	t.Helper()
+	dir := t.TempDir()
+	config := ManagerConfig{
+		ResumePrefix:     "fakeprefix",
+		JournalPath:      filepath.Join(dir, "journal.jsonl"),
+		DeadLetterPath:   filepath.Join(dir, "dead-letter.jsonl"),
+		MaxWriteAttempts: maxAttempts,
+		RetryBackoff:     5 * time.Millisecond,
+	}
+	am, err := NewApplicantManager(store, &stubBlobStore{}, config)
+	if err != nil {
+		t.Fatalf("failed to create manager: %v", err)
+	}
+	t.Cleanup(am.Close)
+	return am, config
+}
+
+func waitFor(t *testing.T, condition func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for condition")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// addQueued submits an application that is expected to be queued for retry
+func addQueued(t *testing.T, am *ApplicantManager, github, name, email string, role string) {
+	t.Helper()
+	_, err := am.AddApplicant(context.Background(), github, name, email, []string{role}, nil)
+	var queued *WriteQueuedError
+	if !errors.As(err, &queued) {
+		t.Fatalf("expected write to be queued, got %v", err)
+	}
+}
+
+func TestFailedWriteIsRetried(t *testing.T) {
+	store := &flakyStore{MemoryStore: NewMemoryStore(), failures: 2}
+	am, _ := newJournaledManager(t, store, 5)
+
+	addQueued(t, am, "candy", "Candy Date", "candy@date.com", "senior-software-engineer")
+
+	waitFor(t, func() bool { return am.journal.pending() == 0 })
+	if _, err := store.GetApplication(context.Background(), "candy"); err != nil {
+		t.Fatalf("expected retried write to be stored: %v", err)
+	}
+}
+
+func TestExhaustedWriteIsDeadLettered(t *testing.T) {
+	store := &flakyStore{MemoryStore: NewMemoryStore(), failures: -1}
+	am, config := newJournaledManager(t, store, 3)
+
+	addQueued(t, am, "candy", "Candy Date", "candy@date.com", "senior-software-engineer")
+
+	waitFor(t, func() bool { return am.journal.pending() == 0 })
+	data, err := os.ReadFile(config.DeadLetterPath)
+	if err != nil {
+		t.Fatalf("expected dead-letter file: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 1 || !strings.Contains(lines[0], "candy@date.com") {
+		t.Fatalf("unexpected dead-letter contents: %s", data)
+	}
+}
+
+// replayJournal retries every pending write in order, as the retry loop
+// would
+func replayJournal(t *testing.T, am *ApplicantManager) {
+	t.Helper()
+	for i := 0; am.journal.pending() > 0; i++ {
+		if i > 10 {
+			t.Fatalf("journal did not drain, %d writes pending", am.journal.pending())
+		}
+		for _, entry := range am.journal.due(time.Now().Add(time.Hour)) {
+			am.retryEntry(entry)
+		}
+	}
+}
+
+// userApplications lists every application of github
+func userApplications(t *testing.T, store ApplicationStore, github string) []Application {
+	t.Helper()
+	apps, _, err := store.ListUserApplications(context.Background(), github, "", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return apps
+}
+
+func TestWritesQueueBehindPendingCreate(t *testing.T) {
+	store := &flakyStore{MemoryStore: NewMemoryStore(), failures: 1}
+	am, config := newJournaledManager(t, store, 5)
+	// Hold off retries so the second write sees the first still pending
+	am.Close()
+
+	addQueued(t, am, "candy", "Candy Date", "candy@date.com", "senior-software-engineer")
+	addQueued(t, am, "candy", "Candy Dated", "candy@example.com", "senior-software-engineer")
+
+	if am.journal.pending() != 1 {
+		t.Fatalf("expected the edit to be folded into the pending create, got %d writes", am.journal.pending())
+	}
+	replayJournal(t, am)
+
+	apps := userApplications(t, store, "candy")
+	if len(apps) != 1 || apps[0].Name != "Candy Dated" || apps[0].Email != "candy@example.com" {
+		t.Fatalf("expected a single application with the latest fields: %+v", apps)
+	}
+	if _, err := os.Stat(config.DeadLetterPath); !os.IsNotExist(err) {
+		t.Fatalf("expected nothing to be dead-lettered: %v", err)
+	}
+}
+
+func TestWritesQueueBehindPendingEdit(t *testing.T) {
+	store := &flakyStore{MemoryStore: NewMemoryStore()}
+	am, config := newJournaledManager(t, store, 5)
+	am.Close()
+	addAndWait(t, am, "candy", "Candy Date", "candy@date.com", "senior-software-engineer")
+
+	store.lock.Lock()
+	store.failures = 1
+	store.lock.Unlock()
+	addQueued(t, am, "candy", "Candy Dated", "candy@date.com", "senior-software-engineer")
+	addQueued(t, am, "candy", "Candy Dated", "candy@example.com", "senior-software-engineer")
+	addQueued(t, am, "candy", "Candy Dates", "candy@example.com", "senior-software-engineer")
+
+	due := am.journal.due(time.Now().Add(time.Hour))
+	if am.journal.pending() != 1 || due[0].WriteState != recreateApp || due[0].PrevEmail != "candy@date.com" {
+		t.Fatalf("expected the edits to be folded into one recreate: %+v", due)
+	}
+	replayJournal(t, am)
+
+	apps := userApplications(t, store, "candy")
+	if len(apps) != 1 || apps[0].Name != "Candy Dates" || apps[0].Email != "candy@example.com" || apps[0].Version != 2 {
+		t.Fatalf("expected the latest edit to be stored: %+v", apps)
+	}
+	if _, err := os.Stat(config.DeadLetterPath); !os.IsNotExist(err) {
+		t.Fatalf("expected nothing to be dead-lettered: %v", err)
+	}
+}
+
+func TestRetryWritesEntryFoldedAfterItWasDue(t *testing.T) {
+	store := &flakyStore{MemoryStore: NewMemoryStore(), failures: 1}
+	am, _ := newJournaledManager(t, store, 5)
+	am.Close()
+
+	addQueued(t, am, "candy", "Candy Date", "candy@date.com", "senior-software-engineer")
+	due := am.journal.due(time.Now().Add(time.Hour))
+	addQueued(t, am, "candy", "Candy Dated", "candy@date.com", "senior-software-engineer")
+	am.retryEntry(due[0])
+
+	apps := userApplications(t, store, "candy")
+	if am.journal.pending() != 0 || len(apps) != 1 || apps[0].Name != "Candy Dated" {
+		t.Fatalf("expected the retry to write the folded edit: %+v", apps)
+	}
+}
+
+func TestJournalSurvivesRestart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal.jsonl")
+	journal, err := newWriteJournal(path, "", 3, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	app := Application{AppliedDate: 100, Github: "candy", Email: "candy@date.com"}
+	journal.add(applicationPacket{app: app, writeState: newApp}, errors.New("timeout"))
+
+	reloaded, err := newWriteJournal(path, "", 3, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reloaded.hasPending("candy") {
+		t.Fatalf("expected journaled write to be reloaded")
+	}
+	entry := reloaded.entries[0]
+	if !reflect.DeepEqual(entry.Record, app) || entry.Attempts != 1 || entry.LastError != "timeout" {
+		t.Fatalf("unexpected reloaded entry: %+v", entry)
+	}
+
+	reloaded.succeeded(entry.Seq)
+	again, _ := newWriteJournal(path, "", 3, time.Second)
+	if again.pending() != 0 {
+		t.Fatalf("expected completed write to be removed from the journal")
+	}
+}
+
+func TestJournalLoadsLargeEntries(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal.jsonl")
+	journal, err := newWriteJournal(path, "", 3, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	answer := strings.Repeat("a long answer ", 20000)
+	app := Application{AppliedDate: 100, Github: "candy", Email: "candy@date.com", Answers: map[string]string{"why": answer}}
+	journal.add(applicationPacket{app: app, before: app, writeState: updateApp}, errors.New("timeout"))
+	journal.add(applicationPacket{app: Application{AppliedDate: 200, Github: "dandy", Email: "dandy@date.com"}, writeState: newApp}, nil)
+
+	reloaded, err := newWriteJournal(path, "", 3, time.Second)
+	if err != nil {
+		t.Fatalf("expected a journal with a large entry to load: %v", err)
+	}
+	if reloaded.pending() != 2 || reloaded.entries[0].Record.Answers["why"] != answer {
+		t.Fatalf("expected both entries to be reloaded intact, got %d", reloaded.pending())
+	}
+}
+
+func TestJournalBackoffIsExponential(t *testing.T) {
+	journal, _ := newWriteJournal("", "", 3, time.Second)
+	expected := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second}
+	for i, want := range expected {
+		if got := journal.delay(i + 1); got != want {
+			t.Errorf("attempt %d: expected %s, got %s", i+1, want, got)
+		}
+	}
+	if got := journal.delay(100); got != maxRetryBackoff {
+		t.Errorf("expected backoff to be capped, got %s", got)
+	}
+}
@@ -0,0 +1,222 @@
+package applicant
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+)
+
+/*
+
+DynamoDBLocker serializes applicant writes across term-apply replicas using
+leases stored in the applications table. Each lease is an item keyed by
+
+> email: lock#<github> <br>
+> applied_date: 0 <br>
+> lock_owner: random token of the holder - string <br>
+> lock_expires: unix time the lease expires - number - DynamoDB TTL attribute <br>
+
+A lease is taken with a conditional put that only succeeds if no lease exists
+or the existing lease has expired, so a crashed replica blocks an applicant
+for at most one TTL. Holders renew their lease while they hold it. If a
+renewal fails the lease may already belong to another replica, so the
+context returned by Lock is cancelled and the holder's writes fail. Lease items
+have no github attribute, so they never appear in the github index.
+
+Waiters in the same process queue on an in-process LockVendor first, so only
+one request per applicant polls DynamoDB at a time.
+
+*/
+
+const leaseKeyPrefix = "lock#"
+
+type DynamoDBLocker struct {
+	svc   dynamodbiface.DynamoDBAPI
+	table string
+	ttl   time.Duration
+	local *LockVendor
+}
+
+func NewDynamoDBLocker(svc dynamodbiface.DynamoDBAPI, table string, ttl time.Duration) *DynamoDBLocker {
+	if ttl <= 0 {
+		ttl = 30 * time.Second
+	}
+	return &DynamoDBLocker{
+		svc:   svc,
+		table: table,
+		ttl:   ttl,
+		local: NewLockVendor(),
+	}
+}
+
+func (d *DynamoDBLocker) LockForName(name string) ApplicantLock {
+	return &leaseLock{
+		locker: d,
+		name:   name,
+		local:  d.local.LockForName(name),
+	}
+}
+
+// Stats reports the leases held or waited on by this replica
+func (d *DynamoDBLocker) Stats() LockStats {
+	return d.local.Stats()
+}
+
+type leaseLock struct {
+	locker *DynamoDBLocker
+	name   string
+	local  ApplicantLock
+	token  string
+	cancel context.CancelFunc // cancels the context returned by Lock
+	stop   chan struct{}
+	wg     sync.WaitGroup
+}
+
+// Lock waits for the in-process lock and then for the lease, polling until
+// the lease is free, expires, or ctx is done
+func (l *leaseLock) Lock(ctx context.Context) (context.Context, error) {
+	if _, err := l.local.Lock(ctx); err != nil {
+		return nil, err
+	}
+
+	l.token = newLeaseToken()
+	retry := l.locker.ttl / 10
+	for {
+		err := l.locker.acquire(ctx, l.name, l.token)
+		if err == nil {
+			break
+		}
+		if !isConditionalCheckFailed(err) {
+			l.local.Unlock()
+			return nil, err
+		}
+		select {
+		case <-ctx.Done():
+			l.local.Unlock()
+			return nil, ctx.Err()
+		case <-time.After(retry):
+		}
+	}
+
+	held, cancel := context.WithCancel(ctx)
+	l.cancel = cancel
+	l.stop = make(chan struct{})
+	l.wg.Add(1)
+	go l.renew()
+	return held, nil
+}
+
+func (l *leaseLock) Unlock() {
+	close(l.stop)
+	l.wg.Wait()
+	l.cancel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), l.locker.ttl)
+	defer cancel()
+	if err := l.locker.release(ctx, l.name, l.token); err != nil {
+		// The lease expires on its own after the TTL
+		log.Printf("Failed to release lease for %s: %v", l.name, err)
+	}
+	l.local.Unlock()
+}
+
+// renew extends the lease until Unlock is called, or until a renewal fails
+// and the holder's context is cancelled
+func (l *leaseLock) renew() {
+	defer l.wg.Done()
+
+	ticker := time.NewTicker(l.locker.ttl / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-l.stop:
+			return
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), l.locker.ttl/3)
+			err := l.locker.extend(ctx, l.name, l.token)
+			cancel()
+			if err != nil {
+				log.Printf("Failed to renew lease for %s, cancelling its writes: %v", l.name, err)
+				l.cancel()
+				return
+			}
+		}
+	}
+}
+
+func (d *DynamoDBLocker) acquire(ctx context.Context, name, token string) error {
+	now := time.Now()
+	_, err := d.svc.PutItemWithContext(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(d.table),
+		Item: map[string]*dynamodb.AttributeValue{
+			"email":        {S: aws.String(leaseKeyPrefix + name)},
+			"applied_date": {N: aws.String("0")},
+			"lock_owner":   {S: aws.String(token)},
+			"lock_expires": {N: aws.String(unixString(now.Add(d.ttl)))},
+		},
+		ConditionExpression: aws.String("attribute_not_exists(email) OR lock_expires < :now"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":now": {N: aws.String(unixString(now))},
+		},
+	})
+	return err
+}
+
+func (d *DynamoDBLocker) extend(ctx context.Context, name, token string) error {
+	_, err := d.svc.UpdateItemWithContext(ctx, &dynamodb.UpdateItemInput{
+		TableName:           aws.String(d.table),
+		Key:                 leaseKey(name),
+		UpdateExpression:    aws.String("SET lock_expires = :expires"),
+		ConditionExpression: aws.String("lock_owner = :owner"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":expires": {N: aws.String(unixString(time.Now().Add(d.ttl)))},
+			":owner":   {S: aws.String(token)},
+		},
+	})
+	return err
+}
+
+func (d *DynamoDBLocker) release(ctx context.Context, name, token string) error {
+	_, err := d.svc.DeleteItemWithContext(ctx, &dynamodb.DeleteItemInput{
+		TableName:           aws.String(d.table),
+		Key:                 leaseKey(name),
+		ConditionExpression: aws.String("lock_owner = :owner"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":owner": {S: aws.String(token)},
+		},
+	})
+	return err
+}
+
+func leaseKey(name string) map[string]*dynamodb.AttributeValue {
+	return map[string]*dynamodb.AttributeValue{
+		"email":        {S: aws.String(leaseKeyPrefix + name)},
+		"applied_date": {N: aws.String("0")},
+	}
+}
+
+func newLeaseToken() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic("Failed to generate lease token")
+	}
+	return hex.EncodeToString(b)
+}
+
+func unixString(t time.Time) string {
+	return strconv.FormatInt(t.Unix(), 10)
+}
+
+func isConditionalCheckFailed(err error) bool {
+	aerr, ok := err.(awserr.Error)
+	return ok && aerr.Code() == dynamodb.ErrCodeConditionalCheckFailedException
+}
@@ -0,0 +1,180 @@
+package applicant
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+)
+
+// fakeLeaseTable implements just enough of DynamoDB for lease items
+type fakeLeaseTable struct {
+	dynamodbiface.DynamoDBAPI
+	lock     sync.Mutex
+	items    map[string]map[string]*dynamodb.AttributeValue
+	acquired int // successful lease puts
+}
+
+func newFakeLeaseTable() *fakeLeaseTable {
+	return &fakeLeaseTable{items: map[string]map[string]*dynamodb.AttributeValue{}}
+}
+
+func conditionFailed() error {
+	return awserr.New(dynamodb.ErrCodeConditionalCheckFailedException, "The conditional request failed", nil)
+}
+
+func (f *fakeLeaseTable) PutItemWithContext(_ aws.Context, input *dynamodb.PutItemInput, _ ...request.Option) (*dynamodb.PutItemOutput, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	key := *input.Item["email"].S
+	if existing, ok := f.items[key]; ok {
+		expires, _ := strconv.ParseInt(*existing["lock_expires"].N, 10, 64)
+		now, _ := strconv.ParseInt(*input.ExpressionAttributeValues[":now"].N, 10, 64)
+		if expires >= now {
+			return nil, conditionFailed()
+		}
+	}
+	f.items[key] = input.Item
+	f.acquired++
+	return &dynamodb.PutItemOutput{}, nil
+}
+
+func (f *fakeLeaseTable) UpdateItemWithContext(_ aws.Context, input *dynamodb.UpdateItemInput, _ ...request.Option) (*dynamodb.UpdateItemOutput, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	existing, ok := f.items[*input.Key["email"].S]
+	if !ok || *existing["lock_owner"].S != *input.ExpressionAttributeValues[":owner"].S {
+		return nil, conditionFailed()
+	}
+	existing["lock_expires"] = input.ExpressionAttributeValues[":expires"]
+	return &dynamodb.UpdateItemOutput{}, nil
+}
+
+func (f *fakeLeaseTable) DeleteItemWithContext(_ aws.Context, input *dynamodb.DeleteItemInput, _ ...request.Option) (*dynamodb.DeleteItemOutput, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	key := *input.Key["email"].S
+	existing, ok := f.items[key]
+	if !ok || *existing["lock_owner"].S != *input.ExpressionAttributeValues[":owner"].S {
+		return nil, conditionFailed()
+	}
+	delete(f.items, key)
+	return &dynamodb.DeleteItemOutput{}, nil
+}
+
+func (f *fakeLeaseTable) leases() int {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return len(f.items)
+}
+
+func TestLeaseExcludesOtherReplicas(t *testing.T) {
+	table := newFakeLeaseTable()
+	first := NewDynamoDBLocker(table, "applications", time.Second)
+	second := NewDynamoDBLocker(table, "applications", time.Second)
+
+	held := first.LockForName("candy")
+	if _, err := held.Lock(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+	if _, err := second.LockForName("candy").Lock(ctx); err == nil {
+		t.Fatalf("a second replica should not acquire a held lease")
+	}
+	if stats := second.Stats(); stats.Live != 0 {
+		t.Fatalf("a failed lease should release the local lock, got %+v", stats)
+	}
+
+	acquired := make(chan error, 1)
+	waiter := second.LockForName("candy")
+	go func() {
+		_, err := waiter.Lock(context.Background())
+		acquired <- err
+	}()
+
+	held.Unlock()
+	if err := <-acquired; err != nil {
+		t.Fatalf("expected lease after release: %v", err)
+	}
+	waiter.Unlock()
+	if table.leases() != 0 {
+		t.Fatalf("expected lease item to be deleted")
+	}
+}
+
+func TestLeaseTakesOverExpiredLease(t *testing.T) {
+	table := newFakeLeaseTable()
+	table.items[leaseKeyPrefix+"candy"] = map[string]*dynamodb.AttributeValue{
+		"email":        {S: aws.String(leaseKeyPrefix + "candy")},
+		"applied_date": {N: aws.String("0")},
+		"lock_owner":   {S: aws.String("crashed-replica")},
+		"lock_expires": {N: aws.String(unixString(time.Now().Add(-time.Minute)))},
+	}
+
+	locker := NewDynamoDBLocker(table, "applications", time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	lock := locker.LockForName("candy")
+	if _, err := lock.Lock(ctx); err != nil {
+		t.Fatalf("expected an expired lease to be taken over: %v", err)
+	}
+	lock.Unlock()
+}
+
+func TestLostLeaseCancelsHolder(t *testing.T) {
+	table := newFakeLeaseTable()
+	locker := NewDynamoDBLocker(table, "applications", 300*time.Millisecond)
+
+	lock := locker.LockForName("candy")
+	ctx, err := lock.Lock(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer lock.Unlock()
+
+	// Another replica takes the lease over, so the next renewal fails
+	table.lock.Lock()
+	table.items[leaseKeyPrefix+"candy"]["lock_owner"] = &dynamodb.AttributeValue{S: aws.String("other-replica")}
+	table.lock.Unlock()
+
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second):
+		t.Fatalf("expected the holder's context to be cancelled once the lease was lost")
+	}
+}
+
+func TestAddApplicantWithLeases(t *testing.T) {
+	store := NewMemoryStore()
+	table := newFakeLeaseTable()
+	am, err := NewApplicantManager(store, &stubBlobStore{}, ManagerConfig{
+		Locks: NewDynamoDBLocker(table, "applications", time.Second),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	addAndWait(t, am, "candy", "Candy Date", "candy@date.com", "senior-software-engineer")
+	if _, err := store.GetApplication(context.Background(), "candy"); err != nil {
+		t.Fatalf("expected application to be stored: %v", err)
+	}
+	if stats := am.LockStats(); stats.Live != 0 {
+		t.Fatalf("expected leases to be released, got %+v", stats)
+	}
+	if table.acquired == 0 || table.leases() != 0 {
+		t.Fatalf("expected the write to take and release a lease, %d taken, %d held", table.acquired, table.leases())
+	}
+}
@@ -1,19 +1,107 @@
 package applicant
 
-import "sync"
+import (
+	"context"
+	"sync"
+)
 
+// Locker vends per-applicant locks so reads and writes for the same
+// applicant do not interleave
+type Locker interface {
+	// LockForName returns the lock for name and takes a reference to it
+	LockForName(name string) ApplicantLock
+	Stats() LockStats
+}
+
+// ApplicantLock is a lock vended for a single name. If Lock returns an error
+// the reference is released and Unlock must not be called; otherwise Unlock
+// releases both the lock and the reference. The context returned by Lock is
+// derived from ctx and is cancelled if the lock is lost while held, so work
+// done under the lock should use it.
+type ApplicantLock interface {
+	Lock(ctx context.Context) (context.Context, error)
+	Unlock()
+}
+
+// LockVendor hands out in-process per-name locks. Locks are reference
+// counted and forgotten once nothing holds or waits on them, so memory use is
+// bounded by the number of names in use rather than every name ever seen.
 type LockVendor struct {
-	locks sync.Map
+	lock  sync.Mutex
+	locks map[string]*NameLock
+}
+
+// NameLock is a mutex vended for a single name
+type NameLock struct {
+	vendor *LockVendor
+	name   string
+	sem    chan struct{} // holds a value while locked
+	refs   int           // guarded by vendor.lock
 }
 
-func (self *LockVendor) LockForName(name string) *sync.Mutex {
-	lock, _ := self.locks.LoadOrStore(name, &sync.Mutex{})
-	if lock, ok := lock.(*sync.Mutex); ok {
-		return lock
+// LockStats describes the locks currently tracked by a Locker
+type LockStats struct {
+	Live       int // names with a lock that is held or waited on
+	References int // outstanding LockForName calls across all names
+}
+
+// LockForName returns the lock for name and takes a reference to it. Every
+// call must be paired with exactly one Lock and Unlock; Unlock releases the
+// reference.
+func (self *LockVendor) LockForName(name string) ApplicantLock {
+	self.lock.Lock()
+	defer self.lock.Unlock()
+
+	lock, ok := self.locks[name]
+	if !ok {
+		lock = &NameLock{vendor: self, name: name, sem: make(chan struct{}, 1)}
+		self.locks[name] = lock
 	}
-	panic("Failed to store mutex")
+	lock.refs++
+	return lock
+}
+
+func (self *LockVendor) Stats() LockStats {
+	self.lock.Lock()
+	defer self.lock.Unlock()
+
+	stats := LockStats{Live: len(self.locks)}
+	for _, lock := range self.locks {
+		stats.References += lock.refs
+	}
+	return stats
+}
+
+func (self *LockVendor) release(lock *NameLock) {
+	self.lock.Lock()
+	defer self.lock.Unlock()
+
+	lock.refs--
+	if lock.refs == 0 {
+		delete(self.locks, lock.name)
+	}
+}
+
+// Lock waits for the lock until ctx is done. In-process locks cannot be
+// lost, so ctx itself is returned.
+func (l *NameLock) Lock(ctx context.Context) (context.Context, error) {
+	select {
+	case l.sem <- struct{}{}:
+		return ctx, nil
+	case <-ctx.Done():
+		l.vendor.release(l)
+		return nil, ctx.Err()
+	}
+}
+
+// Unlock releases the lock and the reference taken by LockForName
+func (l *NameLock) Unlock() {
+	<-l.sem
+	l.vendor.release(l)
 }
 
 func NewLockVendor() *LockVendor {
-	return &LockVendor{}
+	return &LockVendor{
+		locks: map[string]*NameLock{},
+	}
 }
@@ -1,6 +1,10 @@
 package applicant
 
-import "testing"
+import (
+	"context"
+	"sync"
+	"testing"
+)
 
 func TestLockForSameKeyIsSame(t *testing.T) {
 	vendor := NewLockVendor()
@@ -19,3 +23,81 @@ func TestLockForDifferentKeyIsDifferent(t *testing.T) {
 		t.Fatal()
 	}
 }
+
+func TestLockIsForgottenAfterUnlock(t *testing.T) {
+	vendor := NewLockVendor()
+	lock := vendor.LockForName("testing")
+	lock.Lock(context.Background())
+	if stats := vendor.Stats(); stats.Live != 1 || stats.References != 1 {
+		t.Fatalf("expected one live lock, got %+v", stats)
+	}
+	lock.Unlock()
+	if stats := vendor.Stats(); stats.Live != 0 || stats.References != 0 {
+		t.Fatalf("expected no live locks, got %+v", stats)
+	}
+}
+
+func TestLockIsKeptWhileWaitedOn(t *testing.T) {
+	vendor := NewLockVendor()
+	holder := vendor.LockForName("testing")
+	holder.Lock(context.Background())
+
+	waiter := vendor.LockForName("testing")
+	if waiter != holder {
+		t.Fatalf("a waiter must receive the held lock")
+	}
+	acquired := make(chan struct{})
+	go func() {
+		waiter.Lock(context.Background())
+		close(acquired)
+	}()
+
+	holder.Unlock()
+	<-acquired
+	if stats := vendor.Stats(); stats.Live != 1 || stats.References != 1 {
+		t.Fatalf("expected the waiter to keep the lock alive, got %+v", stats)
+	}
+	waiter.Unlock()
+	if stats := vendor.Stats(); stats.Live != 0 {
+		t.Fatalf("expected no live locks, got %+v", stats)
+	}
+}
+
+func TestLockSerializesHolders(t *testing.T) {
+	vendor := NewLockVendor()
+	var wg sync.WaitGroup
+	counter := 0
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			lock := vendor.LockForName("testing")
+			lock.Lock(context.Background())
+			counter++
+			lock.Unlock()
+		}()
+	}
+	wg.Wait()
+	if counter != 100 {
+		t.Fatalf("expected 100 increments, got %d", counter)
+	}
+	if stats := vendor.Stats(); stats.Live != 0 {
+		t.Fatalf("expected no live locks, got %+v", stats)
+	}
+}
+
+func TestLockGivesUpWhenContextIsDone(t *testing.T) {
+	vendor := NewLockVendor()
+	holder := vendor.LockForName("testing")
+	holder.Lock(context.Background())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := vendor.LockForName("testing").Lock(ctx); err == nil {
+		t.Fatalf("expected lock to fail once the context is done")
+	}
+	if stats := vendor.Stats(); stats.References != 1 {
+		t.Fatalf("a failed lock should release its reference, got %+v", stats)
+	}
+	holder.Unlock()
+}
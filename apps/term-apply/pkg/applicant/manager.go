@@ -1,9 +1,16 @@
 package applicant
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
 	"log"
-	"reflect"
+	"strings"
 	"sync"
+	"time"
+
+	"github.com/nebulaworks/orion/apps/term-apply/pkg/blobstore"
 )
 
 type writeState int64
@@ -14,46 +21,154 @@ const (
 	recreateApp writeState = 2
 )
 
+// historyPageSize is how many of a candidate's applications are read at a
+// time when checking their earlier applications
+const historyPageSize = 100
+
+// maxConflictRetries is how many times a submission is re-evaluated when the
+// application changes while it is being saved
+const maxConflictRetries = 3
+
+// ManagerConfig holds the settings for an ApplicantManager. Timeouts of
+// zero or less disable the deadline for that kind of operation.
+type ManagerConfig struct {
+	ResumePrefix string        // blob store prefix where resumes are kept
+	ReadTimeout  time.Duration // deadline for application and resume lookups
+	WriteTimeout time.Duration // deadline for application writes
+
+	JournalPath      string        // where failed writes are kept for retry, in memory when empty
+	DeadLetterPath   string        // where writes go after MaxWriteAttempts failures
+	MaxWriteAttempts int           // attempts before a write is dead-lettered
+	RetryBackoff     time.Duration // delay before the first retry, doubled for each attempt
+
+	WriteWorkers    int // number of writers applying writes in parallel
+	WriteQueueDepth int // writes each writer buffers before AddApplicant blocks
+
+	Locks  Locker       // per-applicant locking, in process only when nil
+	Events EventStore   // audit trail of changes, in memory when nil
+	Roles  *RoleCatalog // roles candidates can apply for, DefaultRoles when nil
+
+	KnockoutCooldown time.Duration // how long a knockout rejection keeps a candidate from applying for the role again
+}
+
 type ApplicantManager struct {
-	locks         *LockVendor            // controls per-applicant locking
-	writeChan     chan applicationPacket // serializes application uploads
-	resumes       *resumeWatcher
-	dynamodbTable string
-	dynamodbIndex string
+	locks     Locker                   // controls per-applicant locking
+	writers   []chan applicationPacket // write queues, sharded by applicant
+	resumes   *resumeWatcher
+	store     ApplicationStore
+	journal   *writeJournal // failed writes waiting to be retried
+	events    EventStore
+	roles     *RoleCatalog
+	config    ManagerConfig
+	done      chan struct{}
+	closeOnce sync.Once
 }
 
 type applicationPacket struct {
-	app           application
+	ctx           context.Context // returned by applicantLock, cancelled if the lock is lost or the session ends
+	app           Application
+	before        Application // the open application being edited, if any
 	prevEmail     string
 	writeState    writeState
-	applicantLock *sync.Mutex
+	applicantLock ApplicantLock
+	counted       []string   // IDs of the roles counted for the write, taken back if nothing is saved
+	result        chan error // buffered, receives the outcome of the write
 }
 
-func NewApplicantManager(bucket, resumePrefix, dynamodbTable, dynamodbIndex string) (*ApplicantManager, error) {
+func NewApplicantManager(store ApplicationStore, blobs blobstore.BlobStore, config ManagerConfig) (*ApplicantManager, error) {
 
-	writeChan := make(chan applicationPacket)
+	resumes, err := newResumeWatcher(blobs, config.ResumePrefix)
+	if err != nil {
+		return nil, err
+	}
 
-	resumes, err := newResumeWatcher(bucket, resumePrefix)
+	if config.MaxWriteAttempts <= 0 {
+		config.MaxWriteAttempts = 1
+	}
+	if config.RetryBackoff <= 0 {
+		config.RetryBackoff = time.Second
+	}
+	if config.WriteWorkers <= 0 {
+		config.WriteWorkers = 1
+	}
+	if config.WriteQueueDepth < 0 {
+		config.WriteQueueDepth = 0
+	}
+	journal, err := newWriteJournal(config.JournalPath, config.DeadLetterPath, config.MaxWriteAttempts, config.RetryBackoff)
 	if err != nil {
 		return nil, err
 	}
 
+	if config.Locks == nil {
+		config.Locks = NewLockVendor()
+	}
+	if config.Events == nil {
+		config.Events = NewMemoryEventStore()
+	}
+	if config.Roles == nil {
+		if config.Roles, err = NewRoleCatalog(DefaultRoles); err != nil {
+			return nil, err
+		}
+	}
+
 	am := &ApplicantManager{
-		locks:         NewLockVendor(),
-		writeChan:     writeChan,
-		resumes:       resumes,
-		dynamodbTable: dynamodbTable,
-		dynamodbIndex: dynamodbIndex,
+		locks:   config.Locks,
+		writers: make([]chan applicationPacket, config.WriteWorkers),
+		resumes: resumes,
+		store:   store,
+		journal: journal,
+		events:  config.Events,
+		roles:   config.Roles,
+		config:  config,
+		done:    make(chan struct{}),
 	}
 
-	go am.writeDynamoItem(writeChan)
+	for i := range am.writers {
+		am.writers[i] = make(chan applicationPacket, config.WriteQueueDepth)
+		go am.writeWorker(am.writers[i])
+	}
+	go am.retryJournal(config.RetryBackoff)
 
 	return am, nil
 }
 
-func (a *ApplicantManager) AddApplicant(github, name, email string, roleApplied int) error {
-	roleStr := stringRole(roleApplied)
-	newApplication, err := NewApplication(github, name, email, roleStr)
+// withTimeout derives a context for a single storage operation
+func withTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// AddApplicant records an application for github and blocks until the write
+// has been persisted or has failed. A write that failed but was queued for
+// retry returns a *WriteQueuedError. If the application keeps changing
+// underneath the submission a *VersionConflictError is returned. ctx should
+// be scoped to the candidate's session. Cancelling it abandons any lookup in
+// progress and a write not yet handed to a writer; a write already being made
+// is cancelled too, but is queued for retry like any other failed write, so
+// it is still saved. roleIDs are the roles applied
+// for, in the candidate's order; applying for a role that is no longer open
+// returns a *RoleClosedError. answers must answer every screening question of
+// the roles; knockout answers flag the application or reject it for a role
+// once it is saved.
+func (a *ApplicantManager) AddApplicant(ctx context.Context, github, name, email string, roleIDs []string, answers map[string]string) (Receipt, error) {
+	roles := make([]Role, 0, len(roleIDs))
+	chosen := map[string]bool{}
+	for _, roleID := range roleIDs {
+		role, ok := a.roles.Role(roleID)
+		if !ok {
+			log.Printf("New applicant %s applied for unknown role %q", github, roleID)
+			return Receipt{}, fmt.Errorf("unknown role %q", roleID)
+		}
+		if chosen[roleID] {
+			log.Printf("New applicant %s applied for role %q more than once", github, roleID)
+			return Receipt{}, fmt.Errorf("repeated role %q", roleID)
+		}
+		chosen[roleID] = true
+		roles = append(roles, role)
+	}
+	newApplication, err := NewApplication(github, name, email, roles...)
 	if err != nil {
 		log.Printf(
 			"New applicant %s error (%v) with (%s, %s, %s)",
@@ -61,28 +176,74 @@ func (a *ApplicantManager) AddApplicant(github, name, email string, roleApplied
 			err,
 			name,
 			email,
-			roleStr,
+			strings.Join(roleIDs, ","),
 		)
-		return err
+		return Receipt{}, err
 	}
+	if err := checkAnswers(roleQuestions(roles), answers); err != nil {
+		log.Printf("New applicant %s error (%v) answering questions for %s", github, err, strings.Join(roleIDs, ","))
+		return Receipt{}, err
+	}
+	newApplication.Answers = answers
+
+	for attempt := 1; ; attempt++ {
+		receipt, err := a.addApplication(ctx, newApplication, roles)
+		var conflict *VersionConflictError
+		if !errors.As(err, &conflict) || attempt == maxConflictRetries {
+			return receipt, err
+		}
+		log.Printf("Application for %s changed while saving, retrying (%v)", github, err)
+	}
+}
+
+// addApplication decides whether newApplication is a new application or an
+// edit of the applicant's open application, and writes it
+func (a *ApplicantManager) addApplication(ctx context.Context, newApplication Application, roles []Role) (Receipt, error) {
+	github, name, email, roleStr := newApplication.Github, newApplication.Name, newApplication.Email, newApplication.RoleTitles()
 
 	lock := a.locks.LockForName(github)
-	lock.Lock()
+	held, err := lock.Lock(ctx)
+	if err != nil {
+		log.Printf("Could not lock applicant %s: %v", github, err)
+		return Receipt{}, err
+	}
 
-	app, err := GetApplication(github, a.dynamodbTable, a.dynamodbIndex)
+	readCtx, cancel := withTimeout(held, a.config.ReadTimeout)
+	app, err := a.store.GetApplication(readCtx, github)
+	cancel()
 
 	// No application exists: new applicant
 	if _, ok := err.(*emptyResultError); ok {
+		if err := a.checkRolesOpen(github, roles); err != nil {
+			lock.Unlock()
+			return Receipt{}, err
+		}
+		if err := a.countRoles(held, github, roles); err != nil {
+			lock.Unlock()
+			return Receipt{}, err
+		}
 		log.Printf("Creating new application for applicant %s with (%s, %s, %s)", github, name, email, roleStr)
-		a.writeChan <- applicationPacket{app: newApplication, writeState: newApp, applicantLock: lock}
-		return nil
+		applyKnockouts(&newApplication, roles, time.Now())
+		return a.submit(ctx, applicationPacket{ctx: held, app: newApplication, writeState: newApp, applicantLock: lock, counted: roleIDs(roles)})
 	} else if err != nil {
 		lock.Unlock()
-		return err
+		return Receipt{}, err
 	}
 
 	// Closed application exists: returning applicant
-	if app.rejected || app.offerGiven {
+	if !app.IsOpen() {
+		if err := a.checkRolesOpen(github, roles); err != nil {
+			lock.Unlock()
+			return Receipt{}, err
+		}
+		if err := a.checkKnockedOut(held, github, roles); err != nil {
+			lock.Unlock()
+			return Receipt{}, err
+		}
+		if err := a.countRoles(held, github, roles); err != nil {
+			lock.Unlock()
+			return Receipt{}, err
+		}
 		log.Printf(
 			"Found closed application for applicant %s, creating new application (%s, %s, %s)",
 			github,
@@ -90,14 +251,17 @@ func (a *ApplicantManager) AddApplicant(github, name, email string, roleApplied
 			email,
 			roleStr,
 		)
-		a.writeChan <- applicationPacket{app: newApplication, writeState: newApp, applicantLock: lock}
-		return nil
+		applyKnockouts(&newApplication, roles, time.Now())
+		return a.submit(ctx, applicationPacket{ctx: held, app: newApplication, writeState: newApp, applicantLock: lock, counted: roleIDs(roles)})
 	}
 
-	// Keep original applied date for open applications
-	newApplication.appliedDate = app.appliedDate
+	// Keep original applied date for open applications, and expect the
+	// version that was read when writing
+	newApplication.AppliedDate = app.AppliedDate
+	newApplication.Version = app.Version
+	newApplication.Roles = mergeAppliedRoles(app.AppliedRoles(), newApplication.Roles)
 
-	if reflect.DeepEqual(newApplication, app) {
+	if newApplication.sameCandidateFields(app) {
 		log.Printf(
 			"Found open application for applicant %s with identical fields, no changes with (%s, %s, %s)",
 			github,
@@ -106,11 +270,27 @@ func (a *ApplicantManager) AddApplicant(github, name, email string, roleApplied
 			roleStr,
 		)
 		lock.Unlock()
-		return nil
+		return newReceipt(app), nil
+	}
+
+	// Candidates may keep editing an application for a role that has since
+	// closed, but may not add a closed role
+	added := addedRoles(app, roles)
+	if err := a.checkRolesOpen(github, added); err != nil {
+		lock.Unlock()
+		return Receipt{}, err
+	}
+	if err := a.checkKnockedOut(held, github, added); err != nil {
+		lock.Unlock()
+		return Receipt{}, err
+	}
+	if err := a.countRoles(held, github, added); err != nil {
+		lock.Unlock()
+		return Receipt{}, err
 	}
 
 	// Updated application with unchanged email
-	if newApplication.email == app.email {
+	if newApplication.Email == app.Email {
 		log.Printf(
 			"Found open application for applicant %s with updated fields, updating in place with (%s, %s, %s)",
 			github,
@@ -118,8 +298,7 @@ func (a *ApplicantManager) AddApplicant(github, name, email string, roleApplied
 			email,
 			roleStr,
 		)
-		a.writeChan <- applicationPacket{app: newApplication, writeState: updateApp, applicantLock: lock}
-		return nil
+		return a.submitEdit(ctx, applicationPacket{ctx: held, app: newApplication, before: app, writeState: updateApp, applicantLock: lock, counted: roleIDs(added)}, roles)
 	}
 
 	// Updated application with modified email (recreate necessary)
@@ -130,52 +309,341 @@ func (a *ApplicantManager) AddApplicant(github, name, email string, roleApplied
 		email,
 		roleStr,
 	)
-	a.writeChan <- applicationPacket{app: newApplication, prevEmail: app.email, writeState: recreateApp, applicantLock: lock}
+	return a.submitEdit(ctx, applicationPacket{ctx: held, app: newApplication, before: app, prevEmail: app.Email, writeState: recreateApp, applicantLock: lock, counted: roleIDs(added)}, roles)
+}
+
+// submitEdit submits an edit of the applicant's open application, then
+// applies any knockout answers the edit gave. An edit queued for retry has
+// its knockouts applied when the retry succeeds.
+func (a *ApplicantManager) submitEdit(ctx context.Context, packet applicationPacket, roles []Role) (Receipt, error) {
+	receipt, err := a.submit(ctx, packet)
+	if err == nil {
+		a.knockoutEdit(ctx, packet.app.Github, roles)
+	}
+	return receipt, err
+}
 
+// checkRolesOpen returns a *RoleClosedError for the first of roles that is
+// closed or outside its schedule, including roles that closed after the
+// candidate was shown them. Limits are checked as the application is counted.
+func (a *ApplicantManager) checkRolesOpen(github string, roles []Role) error {
+	now := time.Now()
+	for _, role := range roles {
+		if reason := role.ClosedReason(now); reason != "" {
+			log.Printf("Applicant %s applied for closed role %s: %s", github, role.ID, reason)
+			return &RoleClosedError{Role: role, Reason: reason}
+		}
+	}
 	return nil
 }
 
-func (a *ApplicantManager) writeDynamoItem(writeChan chan applicationPacket) {
+// countRoles counts github's application for each of roles, or returns a
+// *RoleClosedError for the first of them that is full
+func (a *ApplicantManager) countRoles(ctx context.Context, github string, roles []Role) error {
+	if len(roles) == 0 {
+		return nil
+	}
+	ctx, cancel := withTimeout(ctx, a.config.WriteTimeout)
+	defer cancel()
+
+	err := a.store.CountRoleApplications(ctx, roles)
+	var closed *RoleClosedError
+	if errors.As(err, &closed) {
+		log.Printf("Applicant %s applied for closed role %s: %s", github, closed.Role.ID, closed.Reason)
+		return err
+	} else if err != nil {
+		return fmt.Errorf("failed to count applications: %w", err)
+	}
+	return nil
+}
+
+// uncountRoles takes back the counts of roleIDs for a write of github's
+// application that saved nothing: it was never handed to a writer, the store
+// refused it, or it was dead-lettered. Writes queued for retry stay counted.
+func (a *ApplicantManager) uncountRoles(github string, roleIDs []string) {
+	if len(roleIDs) == 0 {
+		return
+	}
+	ctx, cancel := withTimeout(context.Background(), a.config.WriteTimeout)
+	defer cancel()
+	if err := a.store.UncountRoleApplications(ctx, roleIDs); err != nil {
+		log.Printf("Could not take back role counts for %s: %v", github, err)
+	}
+}
+
+// checkKnockedOut returns a *RoleClosedError for the first of roles a
+// knockout answer rejected github for within the knockout cooldown, in any of
+// their applications
+func (a *ApplicantManager) checkKnockedOut(ctx context.Context, github string, roles []Role) error {
+	cooldown := a.config.KnockoutCooldown
+	if cooldown <= 0 || len(roles) == 0 {
+		return nil
+	}
+	chosen := make(map[string]Role, len(roles))
+	for _, role := range roles {
+		chosen[role.ID] = role
+	}
+
+	ctx, cancel := withTimeout(ctx, a.config.ReadTimeout)
+	defer cancel()
+
+	since := time.Now().Add(-cooldown).Unix()
+	cursor := ""
 	for {
-		packet := <-writeChan
-
-		switch packet.writeState {
-		case newApp:
-			{
-				log.Printf("Writing new record to dynamodb in %s for %s", a.dynamodbTable, packet.app.github)
-				if err := PutApplication(packet.app, a.dynamodbTable); err != nil {
-					log.Printf("Error uploading application for %s in %s: %v", packet.app.github, a.dynamodbTable, err)
-				} else {
-					log.Printf("Succesful write")
-				}
-			}
-		case updateApp:
-			{
-				log.Printf("Updating dynamodb record in %s for %s", a.dynamodbTable, packet.app.github)
-				if err := UpdateApplication(packet.app, a.dynamodbTable); err != nil {
-					log.Printf("Error uploading application for %s in %s: %v", packet.app.github, a.dynamodbTable, err)
-				} else {
-					log.Printf("Succesful write")
+		apps, next, err := a.store.ListUserApplications(ctx, github, cursor, historyPageSize)
+		if err != nil {
+			return fmt.Errorf("failed to read previous applications: %w", err)
+		}
+		for _, app := range apps {
+			for _, applied := range app.AppliedRoles() {
+				role, ok := chosen[applied.ID]
+				if !ok || !applied.KnockedOut || applied.RejectedDate < since {
+					continue
 				}
-			}
-		case recreateApp:
-			{
-				log.Printf("Deleting and recreating dynamodb record in %s for %s", a.dynamodbTable, packet.app.github)
-				if err := RecreateApplication(packet.app, packet.prevEmail, a.dynamodbTable); err != nil {
-					log.Printf("Error uploading application for %s in %s: %v", packet.app.github, a.dynamodbTable, err)
-				} else {
-					log.Printf("Succesful write")
+				until := time.Unix(applied.RejectedDate, 0).Add(cooldown)
+				log.Printf("Applicant %s applied for role %s they were knocked out of until %v", github, role.ID, until)
+				return &RoleClosedError{
+					Role:   role,
+					Reason: fmt.Sprintf("not accepting applications from you until %s", until.UTC().Format(roleTimeFormat)),
 				}
 			}
-		default:
-			{
-				log.Printf("Invalid write state")
-			}
+		}
+		if next == "" {
+			return nil
+		}
+		cursor = next
+	}
+}
+
+// catalogRoles returns the roles in the catalog that app is for
+func (a *ApplicantManager) catalogRoles(app Application) []Role {
+	var roles []Role
+	for _, applied := range app.AppliedRoles() {
+		if role, ok := a.roles.Role(applied.ID); ok {
+			roles = append(roles, role)
+		}
+	}
+	return roles
+}
+
+// addedRoles returns the roles that app is not already for
+func addedRoles(app Application, roles []Role) []Role {
+	applied := map[string]bool{}
+	for _, role := range app.AppliedRoles() {
+		applied[role.ID] = true
+	}
+	var added []Role
+	for _, role := range roles {
+		if !applied[role.ID] {
+			added = append(added, role)
+		}
+	}
+	return added
+}
+
+// Roles returns every role in the catalog, open or not
+func (a *ApplicantManager) Roles() []Role {
+	return a.roles.Roles()
+}
+
+// OpenRoles returns the roles candidates can currently apply for. If
+// applications cannot be counted, roles with a limit are listed anyway and
+// checked again when the candidate applies.
+func (a *ApplicantManager) OpenRoles(ctx context.Context) []Role {
+	roles := a.roles.OpenRoles()
+	var limited []string
+	for _, role := range roles {
+		if role.MaxApplications > 0 {
+			limited = append(limited, role.ID)
+		}
+	}
+	if len(limited) == 0 {
+		return roles
+	}
+
+	ctx, cancel := withTimeout(ctx, a.config.ReadTimeout)
+	defer cancel()
+	counts, err := a.store.RoleApplicationCounts(ctx, limited)
+	if err != nil {
+		log.Printf("Listing roles without checking their limits: %v", err)
+		return roles
+	}
+	var open []Role
+	for _, role := range roles {
+		if checkRoleRoom(role, counts[role.ID]) == nil {
+			open = append(open, role)
+		}
+	}
+	return open
+}
+
+// submit hands packet to the writer and waits for the outcome until the
+// session's ctx is done. The packet's applicant lock must be held and is
+// released by the writer.
+func (a *ApplicantManager) submit(ctx context.Context, packet applicationPacket) (Receipt, error) {
+	packet.result = make(chan error, 1)
+
+	select {
+	case a.writerFor(packet.app.Github) <- packet:
+	case <-ctx.Done():
+		packet.applicantLock.Unlock()
+		a.uncountRoles(packet.app.Github, packet.counted)
+		return Receipt{}, ctx.Err()
+	}
+
+	select {
+	case err := <-packet.result:
+		if err != nil {
+			return Receipt{}, err
+		}
+		return newReceipt(packet.app), nil
+	case <-ctx.Done():
+		return Receipt{}, ctx.Err()
+	}
+}
+
+// writerFor returns the write queue for github. Every write for an applicant
+// goes through the same queue, so their writes are applied in order while
+// writes for other applicants proceed in parallel.
+func (a *ApplicantManager) writerFor(github string) chan applicationPacket {
+	h := fnv.New32a()
+	h.Write([]byte(github))
+	return a.writers[h.Sum32()%uint32(len(a.writers))]
+}
+
+func (a *ApplicantManager) writeWorker(queue chan applicationPacket) {
+	for {
+		packet := <-queue
+
+		if a.journal.hasPending(packet.app.Github) {
+			// Queue behind the applicant's earlier failed writes so they
+			// are replayed in order
+			log.Printf("Pending writes exist for %s, queueing write in journal", packet.app.Github)
+			recounted := a.journal.queue(packet)
+			a.uncountRoles(packet.app.Github, recounted)
+			packet.applicantLock.Unlock()
+			packet.result <- &WriteQueuedError{Err: errPendingWrites}
+			continue
+		}
+
+		ctx, cancel := withTimeout(packet.ctx, a.config.WriteTimeout)
+		err := a.writeApplication(ctx, packet.writeState, packet.app, packet.prevEmail)
+		cancel()
+		if isStaleWrite(err) {
+			// Retrying would overwrite the change, so report it instead
+			log.Printf("Application for %s changed while writing: %v", packet.app.Github, err)
+			a.uncountRoles(packet.app.Github, packet.counted)
+		} else if err != nil {
+			log.Printf("Error uploading application for %s, queueing retry: %v", packet.app.Github, err)
+			a.journal.add(packet, err)
+			err = &WriteQueuedError{Err: err}
+		} else {
+			log.Printf("Succesful write")
+			a.recordWrite(packet.writeState, packet.before, packet.app)
 		}
 		packet.applicantLock.Unlock()
+		packet.result <- err
+	}
+}
+
+func (a *ApplicantManager) writeApplication(ctx context.Context, state writeState, app Application, prevEmail string) error {
+	switch state {
+	case newApp:
+		log.Printf("Writing new record for %s", app.Github)
+		return a.store.PutApplication(ctx, app)
+	case updateApp:
+		log.Printf("Updating record for %s", app.Github)
+		return a.store.UpdateApplication(ctx, app)
+	case recreateApp:
+		log.Printf("Deleting and recreating record for %s", app.Github)
+		return a.store.RecreateApplication(ctx, app, prevEmail)
+	default:
+		return fmt.Errorf("invalid write state %d", state)
 	}
 }
 
-func (a *ApplicantManager) HasResume(github string) bool {
-	return a.resumes.isUploaded(github)
+// retryJournal replays failed writes from the journal until the manager is
+// closed. Retries are not tied to the candidate's session, since it has
+// usually ended by the time a retry happens.
+func (a *ApplicantManager) retryJournal(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-a.done:
+			return
+		case now := <-ticker.C:
+			for _, entry := range a.journal.due(now) {
+				a.retryEntry(entry)
+			}
+		}
+	}
+}
+
+func (a *ApplicantManager) retryEntry(entry journalEntry) {
+	github := entry.Record.Github
+
+	ctx, cancel := withTimeout(context.Background(), a.config.WriteTimeout)
+	defer cancel()
+
+	// Leave the entry due if the applicant is busy; it is retried next tick
+	lock := a.locks.LockForName(github)
+	held, err := lock.Lock(ctx)
+	if err != nil {
+		log.Printf("Could not lock applicant %s for retry: %v", github, err)
+		return
+	}
+
+	// A write may have been folded into the entry while the lock was taken
+	entry, ok := a.journal.entry(entry.Seq)
+	if !ok {
+		lock.Unlock()
+		return
+	}
+	app := entry.Record
+
+	log.Printf("Retrying application write for %s (attempt %d)", app.Github, entry.Attempts+1)
+	if err := a.writeApplication(held, entry.WriteState, app, entry.PrevEmail); err != nil {
+		log.Printf("Retry failed for %s: %v", app.Github, err)
+		if a.journal.failed(entry.Seq, err) {
+			a.uncountRoles(app.Github, entry.Counted)
+		}
+		lock.Unlock()
+		return
+	}
+	log.Printf("Succesful retry for %s", app.Github)
+
+	var before Application
+	if entry.Before != nil {
+		before = *entry.Before
+	}
+	a.recordWrite(entry.WriteState, before, app)
+	a.journal.succeeded(entry.Seq)
+	lock.Unlock()
+
+	// New applications had their knockouts applied before they were queued,
+	// edits only once they are saved
+	if entry.WriteState != newApp {
+		a.knockoutEdit(ctx, app.Github, a.catalogRoles(app))
+	}
+}
+
+// Close stops retrying journaled writes. Pending writes stay in the journal
+// and are retried the next time a manager is created with it.
+func (a *ApplicantManager) Close() {
+	a.closeOnce.Do(func() { close(a.done) })
+}
+
+// LockStats reports how many per-applicant locks are currently live
+func (a *ApplicantManager) LockStats() LockStats {
+	return a.locks.Stats()
+}
+
+// HasResume reports whether github has uploaded a resume. An error means the
+// resume store could not be checked.
+func (a *ApplicantManager) HasResume(ctx context.Context, github string) (bool, error) {
+	ctx, cancel := withTimeout(ctx, a.config.ReadTimeout)
+	defer cancel()
+	return a.resumes.isUploaded(ctx, github)
 }
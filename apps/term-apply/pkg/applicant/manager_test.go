@@ -0,0 +1,364 @@
+package applicant
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func newTestManager(t *testing.T, store ApplicationStore) *ApplicantManager {
+	t.Helper()
+	am, err := NewApplicantManager(store, &stubBlobStore{}, ManagerConfig{ResumePrefix: "fakeprefix"})
+	if err != nil {
+		t.Fatalf("failed to create manager: %v", err)
+	}
+	return am
+}
+
+func addAndWait(t *testing.T, am *ApplicantManager, github, name, email string, role string) Receipt {
+	t.Helper()
+	receipt, err := am.AddApplicant(context.Background(), github, name, email, []string{role}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error adding applicant: %v", err)
+	}
+	return receipt
+}
+
+func TestAddApplicantNew(t *testing.T) {
+	store := NewMemoryStore()
+	am := newTestManager(t, store)
+
+	receipt := addAndWait(t, am, "candy", "Candy Date", "candy@date.com", "senior-software-engineer")
+	if receipt.Email != "candy@date.com" || receipt.AppliedDate.IsZero() {
+		t.Fatalf("unexpected receipt: %+v", receipt)
+	}
+
+	app, err := store.GetApplication(context.Background(), "candy")
+	if err != nil {
+		t.Fatalf("expected application to be stored: %v", err)
+	}
+	if app.Email != "candy@date.com" || app.RoleApplied != "Senior Software Engineer" {
+		t.Fatalf("unexpected application stored: %+v", app)
+	}
+}
+
+func TestAddApplicantUpdatesInPlace(t *testing.T) {
+	store := NewMemoryStore()
+	store.PutApplication(context.Background(), Application{
+		AppliedDate: 100,
+		Github:      "candy",
+		Name:        "Candy Date",
+		Email:       "candy@date.com",
+		RoleApplied: "Software Engineer",
+	})
+	am := newTestManager(t, store)
+
+	addAndWait(t, am, "candy", "Candy Dated", "candy@date.com", "senior-software-engineer")
+
+	app, _ := store.GetApplication(context.Background(), "candy")
+	if app.AppliedDate != 100 {
+		t.Fatalf("applied date should be preserved, got %d", app.AppliedDate)
+	}
+	if app.Name != "Candy Dated" || app.RoleApplied != "Senior Software Engineer" {
+		t.Fatalf("application was not updated: %+v", app)
+	}
+	if len(store.apps) != 1 {
+		t.Fatalf("expected 1 stored application, got %d", len(store.apps))
+	}
+}
+
+func TestAddApplicantRecreatesOnEmailChange(t *testing.T) {
+	store := NewMemoryStore()
+	store.PutApplication(context.Background(), Application{
+		AppliedDate: 100,
+		Github:      "candy",
+		Name:        "Candy Date",
+		Email:       "candy@date.com",
+		RoleApplied: "Software Engineer",
+	})
+	am := newTestManager(t, store)
+
+	addAndWait(t, am, "candy", "Candy Date", "candy@example.com", "software-engineer")
+
+	app, _ := store.GetApplication(context.Background(), "candy")
+	if app.Email != "candy@example.com" || app.AppliedDate != 100 {
+		t.Fatalf("application was not recreated: %+v", app)
+	}
+	if store.find("candy@date.com", 100) != -1 {
+		t.Fatalf("original application should have been removed")
+	}
+}
+
+func TestAddApplicantIdenticalIsNoop(t *testing.T) {
+	store := NewMemoryStore()
+	existing := Application{
+		AppliedDate: 100,
+		Github:      "candy",
+		Name:        "Candy Date",
+		Email:       "candy@date.com",
+		RoleApplied: "Software Engineer",
+		RoleID:      "software-engineer",
+	}
+	store.PutApplication(context.Background(), existing)
+	existing.Version = 1
+	am := newTestManager(t, store)
+
+	receipt := addAndWait(t, am, "candy", "Candy Date", "candy@date.com", "software-engineer")
+	if receipt.AppliedDate.Unix() != 100 {
+		t.Fatalf("receipt should carry the original applied date: %+v", receipt)
+	}
+
+	app, _ := store.GetApplication(context.Background(), "candy")
+	if !reflect.DeepEqual(app, existing) || len(store.apps) != 1 {
+		t.Fatalf("application should not have changed: %+v", app)
+	}
+}
+
+func TestAddApplicantAfterClosedApplication(t *testing.T) {
+	cases := map[string]Application{
+		"rejected":  {Rejected: true},
+		"offer":     {OfferGiven: true},
+		"withdrawn": {State: StateWithdrawn},
+		"declined":  {State: StateDeclined, OfferGiven: true},
+	}
+	for name, closed := range cases {
+		store := NewMemoryStore()
+		closed.AppliedDate = 100
+		closed.Github = "candy"
+		closed.Name = "Candy Date"
+		closed.Email = "candy@date.com"
+		closed.RoleApplied = "Software Engineer"
+		store.PutApplication(context.Background(), closed)
+		am := newTestManager(t, store)
+
+		addAndWait(t, am, "candy", "Candy Date", "candy@date.com", "software-engineer")
+
+		if len(store.apps) != 2 {
+			t.Fatalf("%s: expected a new application, got %d stored", name, len(store.apps))
+		}
+		app, _ := store.GetApplication(context.Background(), "candy")
+		if app.AppliedDate == 100 || app.Rejected || app.OfferGiven || app.CurrentState() != StateApplied {
+			t.Fatalf("%s: latest application should be the new one: %+v", name, app)
+		}
+	}
+}
+
+func TestAddApplicantInvalidInput(t *testing.T) {
+	store := NewMemoryStore()
+	am := newTestManager(t, store)
+
+	if _, err := am.AddApplicant(context.Background(), "candy", "Candy Date", "not-an-email", []string{"senior-software-engineer"}, nil); err == nil {
+		t.Fatalf("expected invalid email to be rejected")
+	}
+	if _, err := store.GetApplication(context.Background(), "candy"); err == nil {
+		t.Fatalf("nothing should have been stored")
+	}
+}
+
+func TestMemoryStoreReturnsLatest(t *testing.T) {
+	store := NewMemoryStore()
+	store.PutApplication(context.Background(), Application{AppliedDate: 200, Github: "candy", Email: "b@date.com"})
+	store.PutApplication(context.Background(), Application{AppliedDate: 100, Github: "candy", Email: "a@date.com"})
+	store.PutApplication(context.Background(), Application{AppliedDate: 300, Github: "other", Email: "c@date.com"})
+
+	app, err := store.GetApplication(context.Background(), "candy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if app.AppliedDate != 200 {
+		t.Fatalf("expected latest application, got %+v", app)
+	}
+
+	if _, err := store.GetApplication(context.Background(), "nobody"); err == nil {
+		t.Fatalf("expected an empty result error")
+	} else if _, ok := err.(*emptyResultError); !ok {
+		t.Fatalf("expected an empty result error, got %T", err)
+	}
+}
+
+func TestAddApplicantCancelledContext(t *testing.T) {
+	store := NewMemoryStore()
+	am := newTestManager(t, store)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := am.AddApplicant(ctx, "candy", "Candy Date", "candy@date.com", []string{"senior-software-engineer"}, nil); err == nil {
+		t.Fatalf("expected cancelled context to abort the submission")
+	}
+	if _, err := store.GetApplication(context.Background(), "candy"); err == nil {
+		t.Fatalf("nothing should have been stored")
+	}
+}
+
+// blockingStore holds PutApplication for blockedUser until release is
+// closed, sending on writing, if set, as the write starts
+type blockingStore struct {
+	*MemoryStore
+	blockedUser string
+	writing     chan struct{}
+	release     chan struct{}
+}
+
+func (b *blockingStore) PutApplication(ctx context.Context, app Application) error {
+	if app.Github == b.blockedUser {
+		if b.writing != nil {
+			b.writing <- struct{}{}
+		}
+		<-b.release
+	}
+	return b.MemoryStore.PutApplication(ctx, app)
+}
+
+func TestSlowWriteDoesNotBlockOtherApplicants(t *testing.T) {
+	store := &blockingStore{MemoryStore: NewMemoryStore(), blockedUser: "slow", release: make(chan struct{})}
+	am, err := NewApplicantManager(store, &stubBlobStore{}, ManagerConfig{WriteWorkers: 4, WriteQueueDepth: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Find an applicant handled by a different writer than the slow one
+	fast := "fast"
+	for i := 0; am.writerFor(fast) == am.writerFor("slow"); i++ {
+		fast = fmt.Sprintf("fast%d", i)
+	}
+
+	slowDone := make(chan error, 1)
+	go func() {
+		_, err := am.AddApplicant(context.Background(), "slow", "Slow Poke", "slow@date.com", []string{"senior-software-engineer"}, nil)
+		slowDone <- err
+	}()
+
+	addAndWait(t, am, fast, "Fast Track", "fast@date.com", "senior-software-engineer")
+
+	select {
+	case <-slowDone:
+		t.Fatalf("slow write should still be blocked")
+	default:
+	}
+	close(store.release)
+	if err := <-slowDone; err != nil {
+		t.Fatalf("unexpected error for slow write: %v", err)
+	}
+}
+
+func TestWriterForIsStable(t *testing.T) {
+	am, _ := NewApplicantManager(NewMemoryStore(), &stubBlobStore{}, ManagerConfig{WriteWorkers: 8})
+	if am.writerFor("candy") != am.writerFor("candy") {
+		t.Fatalf("an applicant must always use the same writer")
+	}
+}
+
+func TestAddApplicantReleasesLocks(t *testing.T) {
+	store := NewMemoryStore()
+	am := newTestManager(t, store)
+
+	for i := 0; i < 10; i++ {
+		addAndWait(t, am, fmt.Sprintf("candy%d", i), "Candy Date", fmt.Sprintf("candy%d@date.com", i), "senior-software-engineer")
+	}
+	if stats := am.LockStats(); stats.Live != 0 {
+		t.Fatalf("expected locks to be released after writes, got %+v", stats)
+	}
+}
+
+func TestMemoryStoreRejectsStaleVersion(t *testing.T) {
+	store := NewMemoryStore()
+	app := Application{AppliedDate: 100, Github: "candy", Name: "Candy Date", Email: "candy@date.com"}
+	store.PutApplication(context.Background(), app)
+
+	if err := store.PutApplication(context.Background(), app); !errors.As(err, new(*VersionConflictError)) {
+		t.Fatalf("expected a version conflict for a duplicate put, got %v", err)
+	}
+
+	app.Version = 1
+	app.Name = "Candy Dated"
+	if err := store.UpdateApplication(context.Background(), app); err != nil {
+		t.Fatal(err)
+	}
+	// app still carries version 1, which is now stale
+	app.Name = "Candy Updated"
+	if err := store.UpdateApplication(context.Background(), app); !errors.As(err, new(*VersionConflictError)) {
+		t.Fatalf("expected a version conflict for a stale update, got %v", err)
+	}
+	if err := store.RecreateApplication(context.Background(), Application{AppliedDate: 100, Github: "candy", Email: "new@date.com", Version: 1}, "candy@date.com"); !errors.As(err, new(*VersionConflictError)) {
+		t.Fatalf("expected a version conflict for a stale recreate, got %v", err)
+	}
+	missing := Application{AppliedDate: 200, Github: "dandy", Email: "dandy@date.com"}
+	if err := store.UpdateApplication(context.Background(), missing); !errors.As(err, new(*ApplicationNotFoundError)) {
+		t.Fatalf("expected an update of a missing application to be refused, got %v", err)
+	}
+
+	stored, _ := store.GetApplication(context.Background(), "candy")
+	if stored.Name != "Candy Dated" || stored.Version != 2 {
+		t.Fatalf("stale writes should not have been applied: %+v", stored)
+	}
+}
+
+// racingStore changes the stored application once, just before the first
+// update, as if another replica had written it concurrently
+type racingStore struct {
+	*MemoryStore
+	raced bool
+}
+
+func (r *racingStore) UpdateApplication(ctx context.Context, app Application) error {
+	if !r.raced {
+		r.raced = true
+		current, _ := r.MemoryStore.GetApplication(ctx, app.Github)
+		current.RoleApplied = "Senior Software Engineer"
+		if err := r.MemoryStore.UpdateApplication(ctx, current); err != nil {
+			return err
+		}
+	}
+	return r.MemoryStore.UpdateApplication(ctx, app)
+}
+
+func TestAddApplicantRetriesVersionConflict(t *testing.T) {
+	store := &racingStore{MemoryStore: NewMemoryStore()}
+	store.PutApplication(context.Background(), Application{
+		AppliedDate: 100,
+		Github:      "candy",
+		Name:        "Candy Date",
+		Email:       "candy@date.com",
+		RoleApplied: "Software Engineer",
+	})
+	am := newTestManager(t, store)
+
+	addAndWait(t, am, "candy", "Candy Dated", "candy@date.com", "software-engineer")
+
+	app, _ := store.GetApplication(context.Background(), "candy")
+	if app.Name != "Candy Dated" || app.RoleApplied != "Software Engineer" || app.Version != 3 {
+		t.Fatalf("update should have been retried against the latest version: %+v", app)
+	}
+}
+
+func TestAddApplicantPreservesStaffFields(t *testing.T) {
+	for name, store := range map[string]ApplicationStore{"memory": NewMemoryStore(), "bolt": newTestBoltStore(t)} {
+		existing := fullApplication()
+		existing.OfferGiven = false
+		existing.OfferAccepted = false
+		existing.Rejected = false
+		existing.State = StateInterviewing
+		existing.Version = 0
+		store.PutApplication(context.Background(), existing)
+		am := newTestManager(t, store)
+
+		addAndWait(t, am, "candy", "Candy Dated", "candy@example.com", "senior-software-engineer")
+
+		app, _ := store.GetApplication(context.Background(), "candy")
+		want := existing
+		want.Name = "Candy Dated"
+		want.Email = "candy@example.com"
+		want.RoleApplied = "Senior Software Engineer"
+		want.RoleID = "senior-software-engineer"
+		// The role the candidate was rejected for is kept
+		want.Roles = []AppliedRole{{ID: "senior-software-engineer", Title: "Senior Software Engineer"}, existing.Roles[1]}
+		want.Answers = nil
+		want.Version = 2
+		if !reflect.DeepEqual(app, want) {
+			t.Fatalf("%s: staff fields should survive a candidate edit:\n got %+v\nwant %+v", name, app, want)
+		}
+	}
+}
@@ -0,0 +1,275 @@
+package applicant
+
+import (
+	"context"
+	"sort"
+	"sync"
+)
+
+// MemoryStore is a thread-safe, in-process ApplicationStore. Nothing is
+// persisted, so it is only suitable for tests and local development.
+type MemoryStore struct {
+	lock   sync.RWMutex
+	apps   []Application
+	counts map[string]int // role ID to applications counted for it
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{counts: map[string]int{}}
+}
+
+// Returns the provided user's most recent application
+func (m *MemoryStore) GetApplication(ctx context.Context, user string) (Application, error) {
+	if err := ctx.Err(); err != nil {
+		return Application{}, err
+	}
+
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	found := -1
+	var latest int64
+	for i, app := range m.apps {
+		if app.Github != user {
+			continue
+		}
+		if found == -1 || app.AppliedDate >= latest {
+			found = i
+			latest = app.AppliedDate
+		}
+	}
+
+	if found == -1 {
+		return Application{}, newEmptyResult(user)
+	}
+	return m.apps[found], nil
+}
+
+func (m *MemoryStore) PutApplication(ctx context.Context, app Application) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	// Never overwrite an existing application
+	if m.find(app.Email, app.AppliedDate) != -1 {
+		return newVersionConflict(app)
+	}
+	app.Version = 1
+	m.apps = append(m.apps, app)
+	return nil
+}
+
+func (m *MemoryStore) UpdateApplication(ctx context.Context, app Application) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	i := m.find(app.Email, app.AppliedDate)
+	if i == -1 {
+		return &ApplicationNotFoundError{Email: app.Email, AppliedDate: app.AppliedDate}
+	}
+	if m.apps[i].Version != app.Version {
+		return newVersionConflict(app)
+	}
+	m.apps[i].setCandidateFields(app)
+	m.apps[i].Version++
+	return nil
+}
+
+func (m *MemoryStore) RecreateApplication(ctx context.Context, app Application, prevEmail string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	i := m.find(prevEmail, app.AppliedDate)
+	if i == -1 {
+		return &ApplicationNotFoundError{Email: prevEmail, AppliedDate: app.AppliedDate}
+	}
+
+	if m.apps[i].Version != app.Version || m.find(app.Email, app.AppliedDate) != -1 {
+		return newVersionConflict(app)
+	}
+
+	// Preserve fields the candidate cannot change
+	record := m.apps[i]
+	record.setCandidateFields(app)
+	record.Version++
+	m.apps[i] = record
+	return nil
+}
+
+func (m *MemoryStore) SaveApplication(ctx context.Context, app Application) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	i := m.find(app.Email, app.AppliedDate)
+	if i == -1 {
+		return &ApplicationNotFoundError{Email: app.Email, AppliedDate: app.AppliedDate}
+	}
+	if m.apps[i].Version != app.Version {
+		return newVersionConflict(app)
+	}
+	app.Version++
+	m.apps[i] = app
+	return nil
+}
+
+// ListApplications pages through applications ordered by email and applied
+// date
+func (m *MemoryStore) ListApplications(ctx context.Context, cursor string, limit int) ([]Application, string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, "", err
+	}
+
+	var afterEmail string
+	var afterApplied int64
+	if cursor != "" {
+		var err error
+		if afterEmail, afterApplied, err = parseApplicationCursor(cursor); err != nil {
+			return nil, "", err
+		}
+	}
+
+	m.lock.RLock()
+	sorted := make([]Application, len(m.apps))
+	copy(sorted, m.apps)
+	m.lock.RUnlock()
+	sort.Slice(sorted, func(i, j int) bool {
+		return applicationLess(sorted[i].Email, sorted[i].AppliedDate, sorted[j].Email, sorted[j].AppliedDate)
+	})
+
+	var page []Application
+	for i, app := range sorted {
+		if cursor != "" && !applicationLess(afterEmail, afterApplied, app.Email, app.AppliedDate) {
+			continue
+		}
+		page = append(page, app)
+		if len(page) == limit {
+			if i < len(sorted)-1 {
+				return page, applicationCursor(app), nil
+			}
+			break
+		}
+	}
+	return page, "", nil
+}
+
+func (m *MemoryStore) ListUserApplications(ctx context.Context, user string, cursor string, limit int) ([]Application, string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, "", err
+	}
+
+	var after Application
+	if cursor != "" {
+		var err error
+		if after.Email, after.AppliedDate, err = parseApplicationCursor(cursor); err != nil {
+			return nil, "", err
+		}
+	}
+
+	m.lock.RLock()
+	var sorted []Application
+	for _, app := range m.apps {
+		if app.Github == user {
+			sorted = append(sorted, app)
+		}
+	}
+	m.lock.RUnlock()
+	sort.Slice(sorted, func(i, j int) bool {
+		return newerApplication(sorted[i], sorted[j])
+	})
+
+	var page []Application
+	for i, app := range sorted {
+		if cursor != "" && !newerApplication(after, app) {
+			continue
+		}
+		page = append(page, app)
+		if len(page) == limit {
+			if i < len(sorted)-1 {
+				return page, applicationCursor(app), nil
+			}
+			break
+		}
+	}
+	return page, "", nil
+}
+
+func (m *MemoryStore) CountRoleApplications(ctx context.Context, roles []Role) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	for _, role := range roles {
+		if err := checkRoleRoom(role, m.counts[role.ID]); err != nil {
+			return err
+		}
+	}
+	for _, role := range roles {
+		m.counts[role.ID]++
+	}
+	return nil
+}
+
+func (m *MemoryStore) UncountRoleApplications(ctx context.Context, roleIDs []string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	for _, id := range roleIDs {
+		m.counts[id]--
+	}
+	return nil
+}
+
+func (m *MemoryStore) RoleApplicationCounts(ctx context.Context, roleIDs []string) (map[string]int, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	counts := make(map[string]int, len(roleIDs))
+	for _, id := range roleIDs {
+		counts[id] = m.counts[id]
+	}
+	return counts, nil
+}
+
+func applicationLess(email string, appliedDate int64, otherEmail string, otherAppliedDate int64) bool {
+	if email != otherEmail {
+		return email < otherEmail
+	}
+	return appliedDate < otherAppliedDate
+}
+
+// find returns the index of the application keyed by email and appliedDate,
+// or -1. Callers must hold the lock.
+func (m *MemoryStore) find(email string, appliedDate int64) int {
+	for i, app := range m.apps {
+		if app.Email == email && app.AppliedDate == appliedDate {
+			return i
+		}
+	}
+	return -1
+}
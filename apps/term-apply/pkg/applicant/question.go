@@ -0,0 +1,212 @@
+package applicant
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+)
+
+/*
+
+Roles can ask candidates screening questions as part of the application.
+A question without choices is answered yes or no. Answers listed under
+"knockout" automatically flag the application for staff, or reject it for
+the role asking the question:
+
+{"id": "work-authorization", "text": "Are you authorized to work in the US?",
+ "knockout": {"No": "reject"}}
+
+{"id": "experience", "text": "Years of professional experience?",
+ "choices": ["0-2", "3-5", "6+"], "knockout": {"0-2": "flag"}}
+
+Answers are stored with the application under the question ID. Roles that
+ask a question with the same ID share the candidate's answer.
+
+*/
+
+// KnockoutAction is what happens to an application given a knockout answer
+type KnockoutAction string
+
+const (
+	KnockoutFlag   KnockoutAction = "flag"   // flag the application for staff
+	KnockoutReject KnockoutAction = "reject" // reject the application for the role
+)
+
+// knockoutActor is who knockout changes are recorded under in the audit trail
+const knockoutActor = "knockout"
+
+// Question is a screening question asked of candidates applying for a role
+type Question struct {
+	ID       string                    `json:"id"`
+	Text     string                    `json:"text"`
+	Choices  []string                  `json:"choices,omitempty"`  // yes or no when empty
+	Knockout map[string]KnockoutAction `json:"knockout,omitempty"` // answer to the action it triggers
+}
+
+// Options returns the answers a candidate can give
+func (q Question) Options() []string {
+	if len(q.Choices) == 0 {
+		return []string{"Yes", "No"}
+	}
+	return q.Choices
+}
+
+func (q Question) hasOption(answer string) bool {
+	for _, option := range q.Options() {
+		if option == answer {
+			return true
+		}
+	}
+	return false
+}
+
+// knockoutFlag is the flag a knockout answer to q sets
+func (q Question) knockoutFlag() string {
+	return "knockout:" + q.ID
+}
+
+// checkQuestions returns the first problem with a role's questions
+func checkQuestions(questions []Question) error {
+	ids := map[string]bool{}
+	for _, q := range questions {
+		switch {
+		case !roleIDPattern.MatchString(q.ID):
+			return fmt.Errorf("question id %q", q.ID)
+		case ids[q.ID]:
+			return fmt.Errorf("duplicate question id %s", q.ID)
+		case strings.TrimSpace(q.Text) == "":
+			return fmt.Errorf("question %s has no text", q.ID)
+		case len(q.Choices) == 1:
+			return fmt.Errorf("question %s has a single choice", q.ID)
+		}
+		ids[q.ID] = true
+		for answer, action := range q.Knockout {
+			if !q.hasOption(answer) {
+				return fmt.Errorf("question %s knocks out unknown answer %q", q.ID, answer)
+			}
+			if action != KnockoutFlag && action != KnockoutReject {
+				return fmt.Errorf("question %s has unknown knockout action %q", q.ID, action)
+			}
+		}
+	}
+	return nil
+}
+
+// checkAnswers returns an error unless every question is answered with one
+// of its options. Answers to other questions are refused too.
+func checkAnswers(questions []Question, answers map[string]string) error {
+	var errs []string
+	asked := map[string]bool{}
+	for _, q := range questions {
+		asked[q.ID] = true
+		if !q.hasOption(answers[q.ID]) {
+			errs = append(errs, q.ID)
+		}
+	}
+	for id := range answers {
+		if !asked[id] {
+			errs = append(errs, id)
+		}
+	}
+	if len(errs) > 0 {
+		sort.Strings(errs)
+		return fmt.Errorf("%d invalid answers %v", len(errs), strings.Join(errs, ","))
+	}
+	return nil
+}
+
+// roleQuestions returns the questions asked by roles, in order and without
+// repeating questions asked by more than one role
+func roleQuestions(roles []Role) []Question {
+	var questions []Question
+	asked := map[string]bool{}
+	for _, role := range roles {
+		for _, q := range role.Questions {
+			if !asked[q.ID] {
+				asked[q.ID] = true
+				questions = append(questions, q)
+			}
+		}
+	}
+	return questions
+}
+
+// sameAnswers reports whether a and b hold the same answers
+func sameAnswers(a, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for id, answer := range a {
+		if other, ok := b[id]; !ok || other != answer {
+			return false
+		}
+	}
+	return true
+}
+
+// applyKnockouts flags app, or rejects it for a role, for its knockout
+// answers to the questions of roles, and reports whether app changed. Flags
+// already set and roles already rejected are left alone.
+func applyKnockouts(app *Application, roles []Role, at time.Time) bool {
+	rejected := map[string]bool{}
+	for _, role := range app.AppliedRoles() {
+		rejected[role.ID] = role.Rejected
+	}
+
+	changed := false
+	for _, role := range roles {
+		for _, q := range role.Questions {
+			switch q.Knockout[app.Answers[q.ID]] {
+			case KnockoutFlag:
+				if _, ok := app.Flags[q.knockoutFlag()]; ok {
+					continue
+				}
+				flags := make(map[string]int64, len(app.Flags)+1)
+				for name, date := range app.Flags {
+					flags[name] = date
+				}
+				flags[q.knockoutFlag()] = at.Unix()
+				app.Flags = flags
+				changed = true
+			case KnockoutReject:
+				if rejected[role.ID] {
+					continue
+				}
+				if app.rejectRole(role.ID, at) == nil {
+					app.editRole(role.ID, func(role *AppliedRole) { role.KnockedOut = true })
+					rejected[role.ID] = true
+					changed = true
+				}
+			}
+		}
+	}
+	return changed
+}
+
+// knockoutEdit applies the knockouts for an edit of github's open
+// application once it has been saved. Failures are logged, since the edit
+// itself succeeded.
+func (a *ApplicantManager) knockoutEdit(ctx context.Context, github string, roles []Role) {
+	readCtx, cancel := withTimeout(ctx, a.config.ReadTimeout)
+	app, err := a.store.GetApplication(readCtx, github)
+	cancel()
+	if err != nil {
+		log.Printf("Could not read application for %s to apply knockouts: %v", github, err)
+		return
+	}
+	if preview := app; !applyKnockouts(&preview, roles, time.Now()) {
+		return
+	}
+
+	log.Printf("Applying knockout answers to application for %s", github)
+	_, err = a.staffEdit(ctx, knockoutActor, app, func(app *Application) error {
+		applyKnockouts(app, roles, time.Now())
+		return nil
+	})
+	if err != nil {
+		log.Printf("Could not apply knockouts to application for %s: %v", github, err)
+	}
+}
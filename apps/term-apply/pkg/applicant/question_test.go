@@ -0,0 +1,155 @@
+package applicant
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func newScreeningManager(t *testing.T, store ApplicationStore, config ManagerConfig) *ApplicantManager {
+	t.Helper()
+	catalog, err := NewRoleCatalog([]Role{{
+		ID: "swe", Title: "Software Engineer", Open: true,
+		Questions: []Question{
+			{ID: "work-authorization", Text: "Are you authorized to work in the US?", Knockout: map[string]KnockoutAction{"No": KnockoutReject}},
+			{ID: "experience", Text: "Years of experience?", Choices: []string{"0-2", "3-5", "6+"}, Knockout: map[string]KnockoutAction{"0-2": KnockoutFlag}},
+		},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	config.Roles = catalog
+	am, err := NewApplicantManager(store, &stubBlobStore{}, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(am.Close)
+	return am
+}
+
+func TestInvalidQuestions(t *testing.T) {
+	invalid := map[string][]Question{
+		"bad id":          {{ID: "Work Authorization", Text: "Authorized?"}},
+		"duplicate id":    {{ID: "a", Text: "A?"}, {ID: "a", Text: "Also A?"}},
+		"no text":         {{ID: "a"}},
+		"single choice":   {{ID: "a", Text: "A?", Choices: []string{"Yes"}}},
+		"unknown answer":  {{ID: "a", Text: "A?", Knockout: map[string]KnockoutAction{"Maybe": KnockoutFlag}}},
+		"unknown action":  {{ID: "a", Text: "A?", Knockout: map[string]KnockoutAction{"No": "close"}}},
+		"choice knockout": {{ID: "a", Text: "A?", Choices: []string{"1", "2"}, Knockout: map[string]KnockoutAction{"No": KnockoutFlag}}},
+	}
+	for name, questions := range invalid {
+		if _, err := NewRoleCatalog([]Role{{ID: "swe", Title: "Software Engineer", Questions: questions}}); err == nil {
+			t.Errorf("%s: expected questions to be rejected", name)
+		}
+	}
+}
+
+func TestAddApplicantChecksAnswers(t *testing.T) {
+	am := newScreeningManager(t, NewMemoryStore(), ManagerConfig{})
+	invalid := []map[string]string{
+		nil,
+		{"work-authorization": "Yes"},
+		{"work-authorization": "Yes", "experience": "10"},
+		{"work-authorization": "Yes", "experience": "6+", "location": "Remote"},
+	}
+	for _, answers := range invalid {
+		if _, err := am.AddApplicant(context.Background(), "candy", "Candy Date", "candy@date.com", []string{"swe"}, answers); err == nil {
+			t.Errorf("expected answers %v to be refused", answers)
+		}
+	}
+}
+
+func TestKnockoutAnswers(t *testing.T) {
+	store := NewMemoryStore()
+	am := newScreeningManager(t, store, ManagerConfig{})
+
+	answers := map[string]string{"work-authorization": "Yes", "experience": "6+"}
+	if _, err := am.AddApplicant(context.Background(), "candy", "Candy Date", "candy@date.com", []string{"swe"}, answers); err != nil {
+		t.Fatal(err)
+	}
+	app, _ := store.GetApplication(context.Background(), "candy")
+	if app.Answers["experience"] != "6+" || len(app.Flags) != 0 || !app.IsOpen() {
+		t.Fatalf("expected the answers to be stored without knockouts: %+v", app)
+	}
+
+	// Editing the application to a knockout answer flags it
+	answers = map[string]string{"work-authorization": "Yes", "experience": "0-2"}
+	if _, err := am.AddApplicant(context.Background(), "candy", "Candy Date", "candy@date.com", []string{"swe"}, answers); err != nil {
+		t.Fatal(err)
+	}
+	app, _ = store.GetApplication(context.Background(), "candy")
+	if _, ok := app.Flags["knockout:experience"]; !ok || app.Answers["experience"] != "0-2" {
+		t.Fatalf("expected the application to be flagged: %+v", app)
+	}
+	events, _ := am.History(context.Background(), "candy")
+	if last := events[len(events)-1]; last.Actor != knockoutActor {
+		t.Fatalf("expected the flag to be recorded as a knockout: %+v", last)
+	}
+
+	// A new application with a knockout answer is rejected as it is saved
+	answers = map[string]string{"work-authorization": "No", "experience": "3-5"}
+	if _, err := am.AddApplicant(context.Background(), "dandy", "Dandy Date", "dandy@date.com", []string{"swe"}, answers); err != nil {
+		t.Fatal(err)
+	}
+	app, _ = store.GetApplication(context.Background(), "dandy")
+	if app.CurrentState() != StateRejected || !app.Rejected || app.Version != 1 {
+		t.Fatalf("expected the application to be rejected: %+v", app)
+	}
+}
+
+func TestKnockoutCooldown(t *testing.T) {
+	store := NewMemoryStore()
+	am := newScreeningManager(t, store, ManagerConfig{KnockoutCooldown: time.Hour})
+
+	answers := map[string]string{"work-authorization": "No", "experience": "6+"}
+	if _, err := am.AddApplicant(context.Background(), "candy", "Candy Date", "candy@date.com", []string{"swe"}, answers); err != nil {
+		t.Fatal(err)
+	}
+	app, _ := store.GetApplication(context.Background(), "candy")
+	if roles := app.AppliedRoles(); !roles[0].KnockedOut || app.IsOpen() {
+		t.Fatalf("expected the application to be knocked out: %+v", app)
+	}
+
+	// Answering differently in a new application does not get around it
+	answers["work-authorization"] = "Yes"
+	_, err := am.AddApplicant(context.Background(), "candy", "Candy Date", "candy@date.com", []string{"swe"}, answers)
+	var closed *RoleClosedError
+	if !errors.As(err, &closed) || closed.Role.ID != "swe" {
+		t.Fatalf("expected the role to be closed to the candidate, got %v", err)
+	}
+
+	// Without a cooldown the candidate may apply again straight away
+	am = newScreeningManager(t, store, ManagerConfig{})
+	if _, err := am.AddApplicant(context.Background(), "candy", "Candy Date", "candy@example.com", []string{"swe"}, answers); err != nil {
+		t.Fatalf("expected the candidate to apply again without a cooldown: %v", err)
+	}
+}
+
+func TestKnockoutAppliedToQueuedEdit(t *testing.T) {
+	store := &flakyStore{MemoryStore: NewMemoryStore()}
+	am := newScreeningManager(t, store, ManagerConfig{MaxWriteAttempts: 3, RetryBackoff: 5 * time.Millisecond})
+
+	answers := map[string]string{"work-authorization": "Yes", "experience": "6+"}
+	if _, err := am.AddApplicant(context.Background(), "candy", "Candy Date", "candy@date.com", []string{"swe"}, answers); err != nil {
+		t.Fatal(err)
+	}
+
+	store.lock.Lock()
+	store.failures = 1
+	store.lock.Unlock()
+	answers = map[string]string{"work-authorization": "No", "experience": "6+"}
+	_, err := am.AddApplicant(context.Background(), "candy", "Candy Date", "candy@date.com", []string{"swe"}, answers)
+	if !errors.As(err, new(*WriteQueuedError)) {
+		t.Fatalf("expected the edit to be queued, got %v", err)
+	}
+
+	waitFor(t, func() bool {
+		app, err := store.GetApplication(context.Background(), "candy")
+		return err == nil && app.CurrentState() == StateRejected
+	})
+	app, _ := store.GetApplication(context.Background(), "candy")
+	if app.Answers["work-authorization"] != "No" || !app.AppliedRoles()[0].KnockedOut {
+		t.Fatalf("expected the retried edit to be knocked out: %+v", app)
+	}
+}
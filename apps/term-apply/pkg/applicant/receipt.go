@@ -0,0 +1,40 @@
+package applicant
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Receipt confirms an application has been persisted
+type Receipt struct {
+	AppliedDate time.Time
+	Name        string
+	Email       string
+	RoleApplied string
+}
+
+func newReceipt(app Application) Receipt {
+	return Receipt{
+		AppliedDate: time.Unix(app.AppliedDate, 0).UTC(),
+		Name:        app.Name,
+		Email:       app.Email,
+		RoleApplied: app.RoleTitles(),
+	}
+}
+
+var errPendingWrites = errors.New("an earlier submission is still being saved")
+
+// WriteQueuedError is returned when an application could not be written
+// immediately and has been journaled to be retried.
+type WriteQueuedError struct {
+	Err error
+}
+
+func (err *WriteQueuedError) Error() string {
+	return fmt.Sprintf("application queued for retry: %v", err.Err)
+}
+
+func (err *WriteQueuedError) Unwrap() error {
+	return err.Err
+}
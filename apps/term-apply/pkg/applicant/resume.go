@@ -1,26 +1,25 @@
 package applicant
 
 import (
+	"context"
 	"fmt"
 
-	"github.com/nebulaworks/orion/apps/term-apply/pkg/s3file"
+	"github.com/nebulaworks/orion/apps/term-apply/pkg/blobstore"
 )
 
-var checkS3KeyExists = s3file.S3keyExists
-
 type resumeWatcher struct {
-	bucket       string
+	blobs        blobstore.BlobStore
 	resumePrefix string
 }
 
-func newResumeWatcher(bucket, resumePrefix string) (*resumeWatcher, error) {
+func newResumeWatcher(blobs blobstore.BlobStore, resumePrefix string) (*resumeWatcher, error) {
 	return &resumeWatcher{
-		bucket:       bucket,
+		blobs:        blobs,
 		resumePrefix: resumePrefix,
 	}, nil
 }
 
-func (r *resumeWatcher) isUploaded(userID string) bool {
+func (r *resumeWatcher) isUploaded(ctx context.Context, userID string) (bool, error) {
 	key := fmt.Sprintf("%s/%s-resume.pdf", r.resumePrefix, userID)
-	return checkS3KeyExists(r.bucket, key)
+	return r.blobs.Exists(ctx, key)
 }
@@ -1,31 +1,39 @@
 package applicant
 
-import "testing"
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// stubBlobStore reports every key as existing or missing
+type stubBlobStore struct {
+	exists bool
+}
 
-func swapS3KeyExists(value func(string, string) bool) {
-	checkS3KeyExists = value
+func (s *stubBlobStore) Put(ctx context.Context, filename, key string) error {
+	return nil
 }
 
-func shouldExist(string, string) bool {
-	return true
+func (s *stubBlobStore) Exists(ctx context.Context, key string) (bool, error) {
+	return s.exists, nil
 }
 
-func shouldNotExist(string, string) bool {
-	return false
+func (s *stubBlobStore) LastModified(ctx context.Context, key string) (time.Time, error) {
+	return time.Time{}, nil
 }
 
 func TestIsUploaded(t *testing.T) {
-	defer swapS3KeyExists(checkS3KeyExists)
-
-	watcher, _ := newResumeWatcher("notarealbucket", "fakeprefix")
+	blobs := &stubBlobStore{}
+	watcher, _ := newResumeWatcher(blobs, "fakeprefix")
 
-	swapS3KeyExists(shouldExist)
-	if !watcher.isUploaded("nothing") {
+	blobs.exists = true
+	if uploaded, _ := watcher.isUploaded(context.Background(), "nothing"); !uploaded {
 		t.Fatalf("It should show as uploaded")
 	}
 
-	swapS3KeyExists(shouldNotExist)
-	if watcher.isUploaded("nothing") {
+	blobs.exists = false
+	if uploaded, _ := watcher.isUploaded(context.Background(), "nothing"); uploaded {
 		t.Fatalf("It should not show as uploaded")
 	}
 }
@@ -1,12 +1,278 @@
 package applicant
 
-func stringRole(i int) string {
-	switch i {
-	case 0:
-		return "Senior Software Engineer"
-	case 1:
-		return "Software Engineer"
-	default:
-		return "Unknown"
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+/*
+
+The roles candidates can apply for come from a RoleCatalog. A catalog loaded
+from a JSON file is reloaded whenever the file changes, so positions can be
+opened and closed without a redeploy. The file holds a list of roles:
+
+[
+  {"id": "senior-software-engineer", "title": "Senior Software Engineer", "open": true, "order": 1},
+  {"id": "software-engineer", "title": "Software Engineer", "open": true, "order": 2,
+   "description_file": "software-engineer.md"}
+]
+
+Roles may also ask screening questions, see question.go.
+
+An open role can be scheduled with "opens_at" and "closes_at", RFC 3339
+times, and can stop accepting applications once it has received
+"max_applications":
+
+  {"id": "intern", "title": "Intern", "open": true,
+   "opens_at": "2026-11-01T00:00:00Z", "closes_at": "2026-12-01T00:00:00Z",
+   "max_applications": 50}
+
+A role's job description is markdown, given inline as "description" or read
+from "description_file", which is relative to the catalog file's directory.
+
+Applications store the role ID, which must never change, alongside the title
+at the time the candidate applied.
+
+*/
+
+// Role is a position candidates can apply for
+type Role struct {
+	ID    string `json:"id"`
+	Title string `json:"title"`
+	Open  bool   `json:"open"`            // accepting applications
+	Order int    `json:"order,omitempty"` // roles are listed in ascending order
+	// Description is the markdown job description shown to candidates
+	Description     string `json:"description,omitempty"`
+	DescriptionFile string `json:"description_file,omitempty"` // read into Description when the catalog loads
+	// Questions are asked of every candidate applying for the role
+	Questions []Question `json:"questions,omitempty"`
+
+	OpensAt         *time.Time `json:"opens_at,omitempty"`         // not accepting applications before
+	ClosesAt        *time.Time `json:"closes_at,omitempty"`        // not accepting applications from
+	MaxApplications int        `json:"max_applications,omitempty"` // applications received before it closes, unlimited when 0
+}
+
+// ClosedReason explains why role is not accepting applications at now, going
+// by its open flag and schedule, or returns "" if it is. Roles that have
+// received MaxApplications are closed by the ApplicantManager.
+func (role Role) ClosedReason(now time.Time) string {
+	switch {
+	case !role.Open:
+		return reasonClosed
+	case role.OpensAt != nil && now.Before(*role.OpensAt):
+		return fmt.Sprintf("not accepting applications until %s", role.OpensAt.UTC().Format(roleTimeFormat))
+	case role.ClosesAt != nil && !now.Before(*role.ClosesAt):
+		return fmt.Sprintf("no longer accepting applications, it closed on %s", role.ClosesAt.UTC().Format(roleTimeFormat))
+	}
+	return ""
+}
+
+// DefaultRoles are offered when no role catalog is configured
+var DefaultRoles = []Role{
+	{
+		ID: "senior-software-engineer", Title: "Senior Software Engineer", Open: true, Order: 1,
+		Description: `## Senior Software Engineer
+
+Lead the design and delivery of cloud native platforms for our clients.
+
+- Own projects from architecture through production
+- Mentor engineers and review their work
+- Several years of experience with **Go**, Kubernetes or Terraform`,
+	},
+	{
+		ID: "software-engineer", Title: "Software Engineer", Open: true, Order: 2,
+		Description: `## Software Engineer
+
+Build and operate cloud native platforms alongside our senior engineers.
+
+- Write, test and ship production code
+- Automate infrastructure and delivery pipelines
+- Experience with a programming language such as **Go** or Python`,
+	},
+}
+
+var roleIDPattern = regexp.MustCompile("^[a-z0-9-]+$")
+
+const roleTimeFormat = "2006-01-02 15:04 MST"
+
+// Reasons a role is not accepting applications, completing "<title> is ..."
+const (
+	reasonClosed = "no longer accepting applications"
+	reasonFull   = "no longer accepting applications, it has received as many as it can take"
+)
+
+// RoleClosedError is returned when a candidate applies for a role that is not
+// accepting applications
+type RoleClosedError struct {
+	Role   Role
+	Reason string // why, completing "<title> is ..."
+}
+
+func (err *RoleClosedError) Error() string {
+	reason := err.Reason
+	if reason == "" {
+		reason = reasonClosed
+	}
+	return fmt.Sprintf("%s is %s", err.Role.Title, reason)
+}
+
+type RoleCatalog struct {
+	path string // reloaded when it changes, empty for a fixed catalog
+
+	lock    sync.Mutex
+	modTime time.Time
+	roles   []Role // in listing order
+}
+
+// NewRoleCatalog returns a fixed catalog of roles
+func NewRoleCatalog(roles []Role) (*RoleCatalog, error) {
+	sorted, err := sortRoles(roles)
+	if err != nil {
+		return nil, err
+	}
+	return &RoleCatalog{roles: sorted}, nil
+}
+
+// LoadRoleCatalog returns a catalog of the roles in the JSON file at path
+func LoadRoleCatalog(path string) (*RoleCatalog, error) {
+	c := &RoleCatalog{path: path}
+	if err := c.reload(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Roles returns every role in listing order
+func (c *RoleCatalog) Roles() []Role {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.path != "" {
+		if info, err := os.Stat(c.path); err == nil && !info.ModTime().Equal(c.modTime) {
+			// Keep serving the previous roles if the new file is broken
+			if err := c.reload(); err != nil {
+				log.Printf("Failed to reload role catalog, keeping %d roles: %v", len(c.roles), err)
+			}
+		}
+	}
+
+	roles := make([]Role, len(c.roles))
+	copy(roles, c.roles)
+	return roles
+}
+
+// OpenRoles returns the roles accepting applications in listing order, going
+// by their open flag and schedule
+func (c *RoleCatalog) OpenRoles() []Role {
+	var open []Role
+	now := time.Now()
+	for _, role := range c.Roles() {
+		if role.ClosedReason(now) == "" {
+			open = append(open, role)
+		}
+	}
+	return open
+}
+
+// Role returns the role with id
+func (c *RoleCatalog) Role(id string) (Role, bool) {
+	for _, role := range c.Roles() {
+		if role.ID == id {
+			return role, true
+		}
+	}
+	return Role{}, false
+}
+
+// reload reads the catalog file. c.lock must be held, except while the
+// catalog is being created.
+func (c *RoleCatalog) reload() error {
+	info, err := os.Stat(c.path)
+	if err != nil {
+		return fmt.Errorf("failed to read role catalog: %w", err)
+	}
+	data, err := os.ReadFile(c.path)
+	if err != nil {
+		return fmt.Errorf("failed to read role catalog: %w", err)
+	}
+	var roles []Role
+	if err := json.Unmarshal(data, &roles); err != nil {
+		return fmt.Errorf("failed to parse role catalog %s: %w", c.path, err)
+	}
+	for i := range roles {
+		if roles[i].DescriptionFile == "" {
+			continue
+		}
+		path := roles[i].DescriptionFile
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(filepath.Dir(c.path), path)
+		}
+		description, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read description of role %s: %w", roles[i].ID, err)
+		}
+		roles[i].Description = string(description)
+	}
+	sorted, err := sortRoles(roles)
+	if err != nil {
+		return err
+	}
+
+	c.roles = sorted
+	c.modTime = info.ModTime()
+	log.Printf("Loaded %d roles from %s", len(sorted), c.path)
+	return nil
+}
+
+// sortRoles checks roles and returns them in listing order
+func sortRoles(roles []Role) ([]Role, error) {
+	ids := map[string]bool{}
+	for i, role := range roles {
+		var errs []string
+		if !roleIDPattern.MatchString(role.ID) {
+			errs = append(errs, "id")
+		} else if ids[role.ID] {
+			errs = append(errs, "duplicate id")
+		}
+		ids[role.ID] = true
+		if !isValidRole(role.Title) {
+			errs = append(errs, "title")
+		}
+		if err := checkQuestions(role.Questions); err != nil {
+			errs = append(errs, err.Error())
+		}
+		if role.OpensAt != nil && role.ClosesAt != nil && !role.ClosesAt.After(*role.OpensAt) {
+			errs = append(errs, "closes_at")
+		}
+		if role.MaxApplications < 0 {
+			errs = append(errs, "max_applications")
+		}
+		if len(errs) > 0 {
+			return nil, fmt.Errorf("invalid role %d (%s): %s", i+1, role.ID, strings.Join(errs, ","))
+		}
+	}
+
+	sorted := make([]Role, len(roles))
+	copy(sorted, roles)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Order < sorted[j].Order
+	})
+	return sorted, nil
+}
+
+// roleIDs returns the IDs of roles, in order
+func roleIDs(roles []Role) []string {
+	ids := make([]string, len(roles))
+	for i, role := range roles {
+		ids[i] = role.ID
 	}
+	return ids
 }
@@ -0,0 +1,302 @@
+package applicant
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func writeRoles(t *testing.T, path, contents string, modTime time.Time) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(contents), 0600); err != nil {
+		t.Fatal(err)
+	}
+	// Make every write visible to the catalog, however quickly it follows
+	// the previous one
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func timePtr(unix int64) *time.Time {
+	t := time.Unix(unix, 0)
+	return &t
+}
+
+func TestRoleCatalogReloads(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "roles.json")
+	writeRoles(t, path, `[
+		{"id": "sre", "title": "Site Reliability Engineer", "open": true, "order": 2},
+		{"id": "swe", "title": "Software Engineer", "open": true, "order": 1},
+		{"id": "intern", "title": "Intern", "open": false, "order": 3}
+	]`, time.Unix(100, 0))
+
+	catalog, err := LoadRoleCatalog(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ids := roleIDs(catalog.OpenRoles()); len(ids) != 2 || ids[0] != "swe" || ids[1] != "sre" {
+		t.Fatalf("unexpected open roles %v", ids)
+	}
+
+	writeRoles(t, path, `[{"id": "swe", "title": "Software Engineer", "open": false}]`, time.Unix(200, 0))
+	if role, ok := catalog.Role("swe"); !ok || role.Open {
+		t.Fatalf("expected the role to be closed after reloading, got %+v", role)
+	}
+
+	// A broken file keeps the roles that were last loaded
+	writeRoles(t, path, `[{"id": "Not An ID", "title": "Software Engineer"}]`, time.Unix(300, 0))
+	if roles := catalog.Roles(); len(roles) != 1 || roles[0].ID != "swe" {
+		t.Fatalf("expected the previous roles to be kept, got %+v", roles)
+	}
+}
+
+func TestInvalidRoles(t *testing.T) {
+	invalid := map[string][]Role{
+		"missing id":   {{Title: "Software Engineer"}},
+		"bad id":       {{ID: "Software Engineer", Title: "Software Engineer"}},
+		"bad title":    {{ID: "swe", Title: "Software Engineer!"}},
+		"duplicate id": {{ID: "swe", Title: "Software Engineer"}, {ID: "swe", Title: "Senior Software Engineer"}},
+		"closes early": {{ID: "swe", Title: "Software Engineer", OpensAt: timePtr(200), ClosesAt: timePtr(100)}},
+		"negative max": {{ID: "swe", Title: "Software Engineer", MaxApplications: -1}},
+	}
+	for name, roles := range invalid {
+		if _, err := NewRoleCatalog(roles); err == nil {
+			t.Errorf("%s: expected roles to be rejected", name)
+		}
+	}
+}
+
+func TestAddApplicantChecksRole(t *testing.T) {
+	store := NewMemoryStore()
+	catalog, _ := NewRoleCatalog([]Role{
+		{ID: "swe", Title: "Software Engineer", Open: true},
+		{ID: "sre", Title: "Site Reliability Engineer", Open: false},
+	})
+	am, err := NewApplicantManager(store, &stubBlobStore{}, ManagerConfig{Roles: catalog})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := am.AddApplicant(context.Background(), "candy", "Candy Date", "candy@date.com", []string{"pm"}, nil); err == nil || !strings.Contains(err.Error(), "unknown role") {
+		t.Fatalf("expected an unknown role to be refused, got %v", err)
+	}
+	if _, err := am.AddApplicant(context.Background(), "candy", "Candy Date", "candy@date.com", []string{"swe", "swe"}, nil); err == nil || !strings.Contains(err.Error(), "repeated role") {
+		t.Fatalf("expected a repeated role to be refused, got %v", err)
+	}
+	if _, err := store.GetApplication(context.Background(), "candy"); !errors.As(err, new(*emptyResultError)) {
+		t.Fatalf("expected nothing to be saved for refused roles, got %v", err)
+	}
+	var closed *RoleClosedError
+	if _, err := am.AddApplicant(context.Background(), "candy", "Candy Date", "candy@date.com", []string{"sre"}, nil); !errors.As(err, &closed) || closed.Role.ID != "sre" {
+		t.Fatalf("expected a closed role to be refused, got %v", err)
+	}
+
+	addAndWait(t, am, "candy", "Candy Date", "candy@date.com", "swe")
+	app, _ := store.GetApplication(context.Background(), "candy")
+	if app.RoleID != "swe" || app.RoleApplied != "Software Engineer" {
+		t.Fatalf("expected the role id and title to be stored: %+v", app)
+	}
+
+	// The open application can be edited after its role closes, but not
+	// moved to another closed role
+	catalog.roles[0].Open = false
+	addAndWait(t, am, "candy", "Candy Dated", "candy@date.com", "swe")
+	if _, err := am.AddApplicant(context.Background(), "candy", "Candy Dated", "candy@date.com", []string{"sre"}, nil); !errors.As(err, &closed) {
+		t.Fatalf("expected moving to a closed role to be refused, got %v", err)
+	}
+	app, _ = store.GetApplication(context.Background(), "candy")
+	if app.Name != "Candy Dated" || app.RoleID != "swe" {
+		t.Fatalf("unexpected application %+v", app)
+	}
+}
+
+func TestRoleDescriptionFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "roles.json")
+	if err := os.WriteFile(filepath.Join(dir, "swe.md"), []byte("## Software Engineer\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	writeRoles(t, path, `[
+		{"id": "swe", "title": "Software Engineer", "open": true, "description_file": "swe.md"},
+		{"id": "sre", "title": "Site Reliability Engineer", "open": true, "description": "Keep it running"}
+	]`, time.Unix(100, 0))
+
+	catalog, err := LoadRoleCatalog(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if role, _ := catalog.Role("swe"); role.Description != "## Software Engineer\n" {
+		t.Fatalf("expected the description to be read from its file, got %q", role.Description)
+	}
+	if role, _ := catalog.Role("sre"); role.Description != "Keep it running" {
+		t.Fatalf("unexpected inline description %q", role.Description)
+	}
+
+	writeRoles(t, path, `[{"id": "swe", "title": "Software Engineer", "description_file": "missing.md"}]`, time.Unix(200, 0))
+	if _, err := LoadRoleCatalog(path); err == nil {
+		t.Fatalf("expected a missing description file to be refused")
+	}
+}
+
+func TestRoleSchedule(t *testing.T) {
+	role := Role{ID: "intern", Title: "Intern", Open: true, OpensAt: timePtr(100), ClosesAt: timePtr(200)}
+	for now, open := range map[int64]bool{50: false, 100: true, 199: true, 200: false} {
+		if reason := role.ClosedReason(time.Unix(now, 0)); (reason == "") != open {
+			t.Errorf("at %d expected open=%t, got reason %q", now, open, reason)
+		}
+	}
+
+	now := time.Now()
+	catalog, _ := NewRoleCatalog([]Role{
+		{ID: "swe", Title: "Software Engineer", Open: true, ClosesAt: &now},
+		{ID: "sre", Title: "Site Reliability Engineer", Open: true},
+	})
+	if ids := roleIDs(catalog.OpenRoles()); len(ids) != 1 || ids[0] != "sre" {
+		t.Fatalf("expected the role past its closing date to be hidden, got %v", ids)
+	}
+}
+
+func TestAddApplicantChecksLimits(t *testing.T) {
+	store := NewMemoryStore()
+	closed := time.Now().Add(-time.Hour)
+	catalog, _ := NewRoleCatalog([]Role{
+		{ID: "swe", Title: "Software Engineer", Open: true, MaxApplications: 1},
+		{ID: "sre", Title: "Site Reliability Engineer", Open: true, ClosesAt: &closed},
+	})
+	am, err := NewApplicantManager(store, &stubBlobStore{}, ManagerConfig{Roles: catalog})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var refused *RoleClosedError
+	if _, err := am.AddApplicant(context.Background(), "candy", "Candy Date", "candy@date.com", []string{"sre"}, nil); !errors.As(err, &refused) || !strings.Contains(err.Error(), "closed on") {
+		t.Fatalf("expected a role past its closing date to be refused, got %v", err)
+	}
+
+	addAndWait(t, am, "candy", "Candy Date", "candy@date.com", "swe")
+	if ids := roleIDs(am.OpenRoles(context.Background())); len(ids) != 0 {
+		t.Fatalf("expected the full role to be hidden, got %v", ids)
+	}
+	if _, err := am.AddApplicant(context.Background(), "dandy", "Dandy Date", "dandy@date.com", []string{"swe"}, nil); !errors.As(err, &refused) || refused.Reason != reasonFull {
+		t.Fatalf("expected the full role to be refused, got %v", err)
+	}
+
+	// Candidates already applying for the role can keep editing
+	addAndWait(t, am, "candy", "Candy Dated", "candy@date.com", "swe")
+}
+
+func TestConcurrentApplicationsKeepToLimit(t *testing.T) {
+	store := NewMemoryStore()
+	catalog, _ := NewRoleCatalog([]Role{{ID: "swe", Title: "Software Engineer", Open: true, MaxApplications: 3}})
+	am, err := NewApplicantManager(store, &stubBlobStore{}, ManagerConfig{Roles: catalog, WriteWorkers: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 10)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			github := fmt.Sprintf("candy%d", i)
+			_, err := am.AddApplicant(context.Background(), github, "Candy Date", github+"@date.com", []string{"swe"}, nil)
+			errs <- err
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+
+	saved := 0
+	for err := range errs {
+		var refused *RoleClosedError
+		switch {
+		case err == nil:
+			saved++
+		case !errors.As(err, &refused) || refused.Reason != reasonFull:
+			t.Fatalf("expected the role to be full, got %v", err)
+		}
+	}
+	counts, _ := store.RoleApplicationCounts(context.Background(), []string{"swe"})
+	if saved != 3 || counts["swe"] != 3 {
+		t.Fatalf("expected exactly 3 applications, saved %d and counted %d", saved, counts["swe"])
+	}
+}
+
+func newLimitedManager(t *testing.T, store ApplicationStore, config ManagerConfig) *ApplicantManager {
+	t.Helper()
+	config.Roles, _ = NewRoleCatalog([]Role{{ID: "swe", Title: "Software Engineer", Open: true, MaxApplications: 2}})
+	am, err := NewApplicantManager(store, &stubBlobStore{}, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(am.Close)
+	return am
+}
+
+func roleCount(t *testing.T, store ApplicationStore, roleID string) int {
+	t.Helper()
+	counts, err := store.RoleApplicationCounts(context.Background(), []string{roleID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return counts[roleID]
+}
+
+func TestCancelledSubmissionIsUncounted(t *testing.T) {
+	store := &blockingStore{MemoryStore: NewMemoryStore(), blockedUser: "candy", writing: make(chan struct{}, 1), release: make(chan struct{})}
+	am := newLimitedManager(t, store, ManagerConfig{})
+
+	// The only writer is busy with candy, so dandy's write is never handed
+	// to it before dandy's session ends
+	done := make(chan error)
+	go func() {
+		_, err := am.AddApplicant(context.Background(), "candy", "Candy Date", "candy@date.com", []string{"swe"}, nil)
+		done <- err
+	}()
+	<-store.writing
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := am.AddApplicant(ctx, "dandy", "Dandy Date", "dandy@date.com", []string{"swe"}, nil); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the submission to be abandoned, got %v", err)
+	}
+	if count := roleCount(t, store, "swe"); count != 1 {
+		t.Fatalf("expected the abandoned submission to be uncounted, got %d", count)
+	}
+
+	close(store.release)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDeadLetteredWriteIsUncounted(t *testing.T) {
+	store := &flakyStore{MemoryStore: NewMemoryStore(), failures: -1}
+	config := ManagerConfig{
+		DeadLetterPath:   filepath.Join(t.TempDir(), "dead-letter.jsonl"),
+		MaxWriteAttempts: 2,
+		RetryBackoff:     5 * time.Millisecond,
+	}
+	am := newLimitedManager(t, store, config)
+
+	// The second submission is folded into the first, which already counted
+	// the role
+	addQueued(t, am, "candy", "Candy Date", "candy@date.com", "swe")
+	addQueued(t, am, "candy", "Candy Dated", "candy@date.com", "swe")
+	if count := roleCount(t, store, "swe"); count != 1 {
+		t.Fatalf("expected the application to be counted once, got %d", count)
+	}
+
+	waitFor(t, func() bool { return am.journal.pending() == 0 })
+	if count := roleCount(t, store, "swe"); count != 0 {
+		t.Fatalf("expected the dead-lettered application to be uncounted, got %d", count)
+	}
+}
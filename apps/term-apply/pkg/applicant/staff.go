@@ -0,0 +1,77 @@
+package applicant
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// ListApplications returns a page of applications for staff to review, and
+// the cursor of the next page
+func (a *ApplicantManager) ListApplications(ctx context.Context, cursor string, limit int) ([]Application, string, error) {
+	ctx, cancel := withTimeout(ctx, a.config.ReadTimeout)
+	defer cancel()
+	return a.store.ListApplications(ctx, cursor, limit)
+}
+
+// ReviewResume records whether the resume for app passed review. app must
+// be the version staff were shown, otherwise a *VersionConflictError is
+// returned.
+func (a *ApplicantManager) ReviewResume(ctx context.Context, actor string, app Application, pass bool) (Application, error) {
+	return a.staffEdit(ctx, actor, app, func(app *Application) error {
+		if app.CurrentState() == StateApplied {
+			if err := app.transition(StateResumeReviewed, time.Now()); err != nil {
+				return err
+			}
+		}
+		app.ResumeReview = pass
+		app.ResumeReviewDate = time.Now().Unix()
+		return nil
+	})
+}
+
+// FlagApplication marks app as flagged by the workflow rule named flag
+func (a *ApplicantManager) FlagApplication(ctx context.Context, actor string, app Application, flag string) (Application, error) {
+	return a.staffEdit(ctx, actor, app, func(app *Application) error {
+		flags := make(map[string]int64, len(app.Flags)+1)
+		for name, date := range app.Flags {
+			flags[name] = date
+		}
+		flags[flag] = time.Now().Unix()
+		app.Flags = flags
+		return nil
+	})
+}
+
+// staffEdit applies edit to app and saves it while holding the applicant's
+// lock, so it cannot interleave with a candidate's own edit. Nothing is saved
+// if edit fails. Staff edits and withdrawals are not journaled, the error is
+// shown to the user instead. Saved edits are recorded in the audit trail under actor.
+func (a *ApplicantManager) staffEdit(ctx context.Context, actor string, app Application, edit func(*Application) error) (Application, error) {
+	lock := a.locks.LockForName(app.Github)
+	ctx, err := lock.Lock(ctx)
+	if err != nil {
+		return app, err
+	}
+	defer lock.Unlock()
+
+	edited := app
+	if err := edit(&edited); err != nil {
+		return app, err
+	}
+
+	ctx, cancel := withTimeout(ctx, a.config.WriteTimeout)
+	defer cancel()
+	if err := a.store.SaveApplication(ctx, edited); err != nil {
+		log.Printf("Staff edit of application for %s failed: %v", app.Github, err)
+		return app, err
+	}
+	edited.Version++
+
+	eventType := EventUpdated
+	if edited.CurrentState() != app.CurrentState() {
+		eventType = EventStateChanged
+	}
+	a.recordEvent(newEvent(eventType, actor, app, edited, time.Now()))
+	return edited, nil
+}
@@ -0,0 +1,71 @@
+package applicant
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestListApplicationsPages(t *testing.T) {
+	for name, store := range map[string]ApplicationStore{"memory": NewMemoryStore(), "bolt": newTestBoltStore(t)} {
+		for i := 0; i < 5; i++ {
+			store.PutApplication(context.Background(), Application{
+				AppliedDate: int64(100 + i),
+				Github:      fmt.Sprintf("candy%d", i),
+				Email:       fmt.Sprintf("candy%d@date.com", i),
+			})
+		}
+
+		seen := map[string]bool{}
+		cursor, pages := "", 0
+		for {
+			page, next, err := store.ListApplications(context.Background(), cursor, 2)
+			if err != nil {
+				t.Fatalf("%s: %v", name, err)
+			}
+			if len(page) > 2 {
+				t.Fatalf("%s: page exceeds limit: %d", name, len(page))
+			}
+			for _, app := range page {
+				if seen[app.Github] {
+					t.Fatalf("%s: %s listed twice", name, app.Github)
+				}
+				seen[app.Github] = true
+			}
+			pages++
+			if next == "" {
+				break
+			}
+			cursor = next
+		}
+		if len(seen) != 5 || pages != 3 {
+			t.Fatalf("%s: expected 5 applications over 3 pages, got %d over %d", name, len(seen), pages)
+		}
+	}
+}
+
+func TestReviewResume(t *testing.T) {
+	store := NewMemoryStore()
+	am := newTestManager(t, store)
+	addAndWait(t, am, "candy", "Candy Date", "candy@date.com", "software-engineer")
+	app, _ := store.GetApplication(context.Background(), "candy")
+
+	reviewed, err := am.ReviewResume(context.Background(), "staff", app, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stored, _ := store.GetApplication(context.Background(), "candy")
+	if !stored.ResumeReview || stored.ResumeReviewDate == 0 || stored.Version != reviewed.Version {
+		t.Fatalf("resume review was not saved: %+v", stored)
+	}
+
+	// A second reviewer still looking at the original version
+	if _, err := am.ReviewResume(context.Background(), "staff", app, false); !errors.As(err, new(*VersionConflictError)) {
+		t.Fatalf("expected a version conflict for a stale review, got %v", err)
+	}
+	stored, _ = store.GetApplication(context.Background(), "candy")
+	if !stored.ResumeReview {
+		t.Fatalf("stale review should not have been saved")
+	}
+}
@@ -0,0 +1,173 @@
+package applicant
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+)
+
+/*
+
+An application moves through the following states:
+
+> applied -> resume_reviewed -> interviewing -> offer -> accepted | declined
+
+It can be rejected from any state before the offer is answered. The
+candidate can withdraw it while it is open; an offer they turn down is
+declined instead. Accepted, declined, rejected and withdrawn are final; a candidate
+who applies again gets a new application.
+
+Every transition is appended to the state history with its time. Items
+written before states existed are given the state their booleans imply.
+
+*/
+
+type State string
+
+const (
+	StateApplied        State = "applied"
+	StateResumeReviewed State = "resume_reviewed"
+	StateInterviewing   State = "interviewing"
+	StateOffer          State = "offer"
+	StateAccepted       State = "accepted"
+	StateDeclined       State = "declined"
+	StateRejected       State = "rejected"
+	StateWithdrawn      State = "withdrawn"
+)
+
+var transitions = map[State][]State{
+	StateApplied:        {StateResumeReviewed, StateInterviewing, StateRejected, StateWithdrawn},
+	StateResumeReviewed: {StateInterviewing, StateRejected, StateWithdrawn},
+	StateInterviewing:   {StateOffer, StateRejected, StateWithdrawn},
+	StateOffer:          {StateAccepted, StateDeclined, StateRejected},
+}
+
+// StateChange records when an application entered a state
+type StateChange struct {
+	State State `json:"state" dynamodbav:"state"`
+	Date  int64 `json:"date" dynamodbav:"date"` // unix time
+}
+
+// TransitionError is returned for a transition the state machine does not
+// allow
+type TransitionError struct {
+	From State
+	To   State
+}
+
+func (err *TransitionError) Error() string {
+	return fmt.Sprintf("an application cannot move from %s to %s", err.From, err.To)
+}
+
+// CurrentState returns the state of app, inferring it for items written
+// before states were recorded
+func (app Application) CurrentState() State {
+	switch {
+	case app.State != "":
+		return app.State
+	case app.Rejected:
+		return StateRejected
+	case app.OfferAccepted:
+		return StateAccepted
+	case app.OfferGiven:
+		return StateOffer
+	case len(app.Interviews) > 0:
+		return StateInterviewing
+	case app.ResumeReviewDate != 0:
+		return StateResumeReviewed
+	default:
+		return StateApplied
+	}
+}
+
+// IsOpen reports whether app is still in progress. Candidates edit their
+// open application instead of starting a new one.
+func (app Application) IsOpen() bool {
+	switch app.CurrentState() {
+	case StateApplied, StateResumeReviewed, StateInterviewing:
+		return true
+	default:
+		return false
+	}
+}
+
+// NextStates returns the states app may move to
+func (app Application) NextStates() []State {
+	return transitions[app.CurrentState()]
+}
+
+// CanTransition reports whether app may move to state
+func (app Application) CanTransition(state State) bool {
+	for _, next := range app.NextStates() {
+		if next == state {
+			return true
+		}
+	}
+	return false
+}
+
+// StateDate returns when app entered state, or 0 if it never did
+func (app Application) StateDate(state State) int64 {
+	for i := len(app.StateHistory) - 1; i >= 0; i-- {
+		if app.StateHistory[i].State == state {
+			return app.StateHistory[i].Date
+		}
+	}
+	return 0
+}
+
+// transition moves app to state at the given time, keeping the documented
+// offer and rejection attributes in step with it
+func (app *Application) transition(state State, at time.Time) error {
+	if !app.CanTransition(state) {
+		return &TransitionError{From: app.CurrentState(), To: state}
+	}
+
+	app.State = state
+	history := make([]StateChange, len(app.StateHistory), len(app.StateHistory)+1)
+	copy(history, app.StateHistory)
+	app.StateHistory = append(history, StateChange{State: state, Date: at.Unix()})
+
+	switch state {
+	case StateOffer:
+		app.OfferGiven = true
+		app.OfferDate = at.Unix()
+	case StateAccepted:
+		app.OfferAccepted = true
+		app.OfferAcceptedDate = at.Unix()
+	case StateRejected:
+		app.Rejected = true
+		app.RejectedDate = at.Unix()
+	}
+	return nil
+}
+
+// WithdrawApplication moves github's latest application to withdrawn at the
+// candidate's request, so their next submission starts a new application.
+// Only open applications can be withdrawn, otherwise a *TransitionError is
+// returned.
+func (a *ApplicantManager) WithdrawApplication(ctx context.Context, github string) (Application, error) {
+	readCtx, cancel := withTimeout(ctx, a.config.ReadTimeout)
+	app, err := a.store.GetApplication(readCtx, github)
+	cancel()
+	if err != nil {
+		return Application{}, err
+	}
+	if !app.IsOpen() {
+		return Application{}, &TransitionError{From: app.CurrentState(), To: StateWithdrawn}
+	}
+
+	log.Printf("Withdrawing application for %s", github)
+	return a.staffEdit(ctx, github, app, func(app *Application) error {
+		return app.transition(StateWithdrawn, time.Now())
+	})
+}
+
+// TransitionApplication moves app to state. app must be the version staff
+// were shown, otherwise a *VersionConflictError is returned.
+func (a *ApplicantManager) TransitionApplication(ctx context.Context, actor string, app Application, state State) (Application, error) {
+	return a.staffEdit(ctx, actor, app, func(app *Application) error {
+		return app.transition(state, time.Now())
+	})
+}
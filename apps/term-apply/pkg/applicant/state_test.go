@@ -0,0 +1,150 @@
+package applicant
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestLegacyApplicationState(t *testing.T) {
+	cases := map[State]Application{
+		StateApplied:        {},
+		StateResumeReviewed: {ResumeReviewDate: 100},
+		StateInterviewing:   {ResumeReviewDate: 100, Interviews: map[string]Interview{"1": {Date: 200}}},
+		StateOffer:          {OfferGiven: true},
+		StateAccepted:       {OfferGiven: true, OfferAccepted: true},
+		StateRejected:       {OfferGiven: true, Rejected: true},
+	}
+	for want, app := range cases {
+		if got := app.CurrentState(); got != want {
+			t.Errorf("expected %s, got %s", want, got)
+		}
+	}
+}
+
+func TestIsOpen(t *testing.T) {
+	open := map[State]bool{
+		StateApplied:        true,
+		StateResumeReviewed: true,
+		StateInterviewing:   true,
+		StateOffer:          false,
+		StateAccepted:       false,
+		StateDeclined:       false,
+		StateRejected:       false,
+		StateWithdrawn:      false,
+	}
+	for state, want := range open {
+		if got := (Application{State: state}).IsOpen(); got != want {
+			t.Errorf("%s: expected open to be %t", state, want)
+		}
+	}
+}
+
+func TestTransitions(t *testing.T) {
+	app := Application{State: StateApplied}
+	path := []State{StateResumeReviewed, StateInterviewing, StateOffer, StateAccepted}
+	for i, state := range path {
+		if err := app.transition(state, time.Unix(int64(100*(i+1)), 0)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !app.OfferGiven || app.OfferDate != 300 || !app.OfferAccepted || app.OfferAcceptedDate != 400 {
+		t.Fatalf("offer attributes should follow the state: %+v", app)
+	}
+	if len(app.StateHistory) != 4 || app.StateDate(StateInterviewing) != 200 {
+		t.Fatalf("unexpected history %+v", app.StateHistory)
+	}
+
+	// Final states cannot move
+	var transitionErr *TransitionError
+	if err := app.transition(StateRejected, time.Now()); !errors.As(err, &transitionErr) {
+		t.Fatalf("expected a transition error, got %v", err)
+	}
+	if err := (&Application{State: StateApplied}).transition(StateOffer, time.Now()); !errors.As(err, &transitionErr) {
+		t.Fatalf("expected an offer before interviews to be refused, got %v", err)
+	}
+}
+
+func TestTransitionApplication(t *testing.T) {
+	store := NewMemoryStore()
+	am := newTestManager(t, store)
+	addAndWait(t, am, "candy", "Candy Date", "candy@date.com", "software-engineer")
+	app, _ := store.GetApplication(context.Background(), "candy")
+
+	app, err := am.ReviewResume(context.Background(), "staff", app, true)
+	if err != nil || app.CurrentState() != StateResumeReviewed {
+		t.Fatalf("expected the review to move the state, got %s: %v", app.CurrentState(), err)
+	}
+	if _, err := am.TransitionApplication(context.Background(), "staff", app, StateAccepted); err == nil {
+		t.Fatalf("expected an invalid transition to be refused")
+	}
+	if _, err := am.TransitionApplication(context.Background(), "staff", app, StateRejected); err != nil {
+		t.Fatal(err)
+	}
+
+	stored, _ := store.GetApplication(context.Background(), "candy")
+	if stored.CurrentState() != StateRejected || !stored.Rejected || stored.RejectedDate == 0 || stored.IsOpen() {
+		t.Fatalf("rejection was not saved: %+v", stored)
+	}
+}
+
+func TestWithdrawApplication(t *testing.T) {
+	store := NewMemoryStore()
+	am := newTestManager(t, store)
+	if _, err := am.WithdrawApplication(context.Background(), "candy"); !errors.As(err, new(*emptyResultError)) {
+		t.Fatalf("expected nothing to withdraw, got %v", err)
+	}
+
+	app, _ := NewApplication("candy", "Candy Date", "candy@date.com", DefaultRoles[1])
+	app.AppliedDate = 100
+	store.PutApplication(context.Background(), app)
+
+	withdrawn, err := am.WithdrawApplication(context.Background(), "candy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	stored, _ := store.GetApplication(context.Background(), "candy")
+	if stored.CurrentState() != StateWithdrawn || stored.StateDate(StateWithdrawn) == 0 || stored.Version != withdrawn.Version {
+		t.Fatalf("withdrawal was not saved: %+v", stored)
+	}
+	if status := statusFromApplication(stored, time.Now()); status.CanWithdraw || !status.Closed {
+		t.Fatalf("withdrawn application should be closed: %+v", status)
+	}
+	if _, err := am.WithdrawApplication(context.Background(), "candy"); !errors.As(err, new(*TransitionError)) {
+		t.Fatalf("expected a second withdrawal to be refused, got %v", err)
+	}
+
+	// The next submission is a brand new application
+	addAndWait(t, am, "candy", "Candy Date", "candy@date.com", "software-engineer")
+	latest, _ := store.GetApplication(context.Background(), "candy")
+	if latest.AppliedDate == 100 || latest.CurrentState() != StateApplied {
+		t.Fatalf("expected a new application after withdrawing, got %+v", latest)
+	}
+	if original := store.apps[store.find("candy@date.com", 100)]; original.CurrentState() != StateWithdrawn {
+		t.Fatalf("withdrawn application should be kept: %+v", original)
+	}
+}
+
+func TestWithdrawRefusedAfterOffer(t *testing.T) {
+	store := NewMemoryStore()
+	am := newTestManager(t, store)
+	app, _ := NewApplication("candy", "Candy Date", "candy@date.com", DefaultRoles[1])
+	app.AppliedDate = 100
+	app.State = StateOffer
+	store.PutApplication(context.Background(), app)
+
+	if status := statusFromApplication(app, time.Now()); status.CanWithdraw {
+		t.Fatalf("an offer should be declined, not withdrawn: %+v", status)
+	}
+	if _, err := am.WithdrawApplication(context.Background(), "candy"); !errors.As(err, new(*TransitionError)) {
+		t.Fatalf("expected withdrawing an offer to be refused, got %v", err)
+	}
+	if app.CanTransition(StateWithdrawn) {
+		t.Fatalf("an offer should not move to withdrawn")
+	}
+	stored, _ := store.GetApplication(context.Background(), "candy")
+	if stored.CurrentState() != StateOffer {
+		t.Fatalf("expected the offer to be kept, got %s", stored.CurrentState())
+	}
+}
@@ -0,0 +1,207 @@
+package applicant
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+)
+
+const defaultRejectionMessage = "Thank you for your interest in Nebulaworks. We have decided not to move forward with your application at this time."
+
+// Status is the candidate-facing view of an application. It is built only
+// from fields that are safe to show the candidate, so interview notes and
+// results never leave the applicant package through it.
+type Status struct {
+	AppliedDate time.Time
+	RoleApplied string       // titles of every role applied for
+	Roles       []RoleStatus // where the application stands for each role
+	Steps       []StatusStep
+	Next        string // the stage the application is waiting on, empty once closed
+	Closed      bool   // the application is no longer open
+	CanWithdraw bool   // the candidate may still withdraw the application
+	Message     string // shown to rejected candidates
+}
+
+// RoleStatus is the candidate-facing outcome of an application for one role
+type RoleStatus struct {
+	Title   string
+	Outcome string
+}
+
+// PastApplication is the candidate-facing summary of one of their
+// applications
+type PastApplication struct {
+	AppliedDate time.Time
+	RoleApplied string
+	Outcome     string
+}
+
+// StatusStep is a single entry in the status timeline
+type StatusStep struct {
+	Title string
+	Date  time.Time
+	Done  bool // false for steps that are scheduled but have not happened
+}
+
+func statusFromApplication(app Application, now time.Time) Status {
+	status := Status{
+		AppliedDate: time.Unix(app.AppliedDate, 0).UTC(),
+		RoleApplied: app.RoleTitles(),
+	}
+	for _, role := range app.AppliedRoles() {
+		outcome := app.outcome()
+		if role.Rejected {
+			outcome = "Not selected"
+		}
+		status.Roles = append(status.Roles, RoleStatus{Title: role.Title, Outcome: outcome})
+	}
+	status.Steps = append(status.Steps, StatusStep{
+		Title: "Application received",
+		Date:  status.AppliedDate,
+		Done:  true,
+	})
+
+	if app.ResumeReviewDate != 0 {
+		status.Steps = append(status.Steps, StatusStep{
+			Title: "Resume reviewed",
+			Date:  time.Unix(app.ResumeReviewDate, 0).UTC(),
+			Done:  true,
+		})
+	}
+
+	for _, round := range app.InterviewRounds() {
+		interview := app.Interviews[round]
+		date := time.Unix(interview.Date, 0).UTC()
+		step := StatusStep{Title: fmt.Sprintf("Interview %s", round), Date: date, Done: true}
+		if interview.Date == 0 || date.After(now) {
+			step.Title = fmt.Sprintf("Interview %s scheduled", round)
+			step.Done = false
+		}
+		status.Steps = append(status.Steps, step)
+	}
+
+	if app.OfferGiven {
+		status.Steps = append(status.Steps, StatusStep{
+			Title: "Offer extended",
+			Date:  time.Unix(app.OfferDate, 0).UTC(),
+			Done:  true,
+		})
+		if app.OfferAccepted {
+			status.Steps = append(status.Steps, StatusStep{
+				Title: "Offer accepted",
+				Date:  time.Unix(app.OfferAcceptedDate, 0).UTC(),
+				Done:  true,
+			})
+		}
+	}
+
+	status.Closed = !app.IsOpen()
+	status.CanWithdraw = app.IsOpen()
+	switch app.CurrentState() {
+	case StateRejected:
+		status.Steps = append(status.Steps, StatusStep{
+			Title: "Application closed",
+			Date:  time.Unix(app.RejectedDate, 0).UTC(),
+			Done:  true,
+		})
+		status.Message = defaultRejectionMessage
+		if len(app.RejectedMsgOverride) > 0 {
+			status.Message = string(app.RejectedMsgOverride)
+		}
+	case StateDeclined:
+		status.Steps = append(status.Steps, StatusStep{
+			Title: "Offer declined",
+			Date:  time.Unix(app.StateDate(StateDeclined), 0).UTC(),
+			Done:  true,
+		})
+	case StateWithdrawn:
+		status.Steps = append(status.Steps, StatusStep{
+			Title: "Application withdrawn",
+			Date:  time.Unix(app.StateDate(StateWithdrawn), 0).UTC(),
+			Done:  true,
+		})
+	case StateApplied:
+		status.Next = "Resume review"
+	case StateResumeReviewed, StateInterviewing:
+		status.Next = "Interviews"
+	}
+	return status
+}
+
+// InterviewRounds returns the keys of app.Interviews in round order
+func (app Application) InterviewRounds() []string {
+	rounds := make([]string, 0, len(app.Interviews))
+	for round := range app.Interviews {
+		rounds = append(rounds, round)
+	}
+	sort.Slice(rounds, func(i, j int) bool {
+		a, errA := strconv.Atoi(rounds[i])
+		b, errB := strconv.Atoi(rounds[j])
+		if errA != nil || errB != nil {
+			return rounds[i] < rounds[j]
+		}
+		return a < b
+	})
+	return rounds
+}
+
+// ApplicationStatus returns the status of github's latest application, or
+// nil if they have not applied
+func (a *ApplicantManager) ApplicationStatus(ctx context.Context, github string) (*Status, error) {
+	ctx, cancel := withTimeout(ctx, a.config.ReadTimeout)
+	defer cancel()
+
+	app, err := a.store.GetApplication(ctx, github)
+	var empty *emptyResultError
+	if errors.As(err, &empty) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	status := statusFromApplication(app, time.Now())
+	return &status, nil
+}
+
+// ApplicationHistory returns up to limit of github's applications after
+// cursor, most recent first, and the cursor of the next page
+func (a *ApplicantManager) ApplicationHistory(ctx context.Context, github string, cursor string, limit int) ([]PastApplication, string, error) {
+	ctx, cancel := withTimeout(ctx, a.config.ReadTimeout)
+	defer cancel()
+
+	apps, next, err := a.store.ListUserApplications(ctx, github, cursor, limit)
+	if err != nil {
+		return nil, "", err
+	}
+	history := make([]PastApplication, len(apps))
+	for i, app := range apps {
+		history[i] = PastApplication{
+			AppliedDate: time.Unix(app.AppliedDate, 0).UTC(),
+			RoleApplied: app.RoleTitles(),
+			Outcome:     app.outcome(),
+		}
+	}
+	return history, next, nil
+}
+
+// outcome describes where app ended up in terms that are safe to show the
+// candidate
+func (app Application) outcome() string {
+	switch app.CurrentState() {
+	case StateOffer:
+		return "Offer extended"
+	case StateAccepted:
+		return "Offer accepted"
+	case StateDeclined:
+		return "Offer declined"
+	case StateRejected:
+		return "Not selected"
+	case StateWithdrawn:
+		return "Withdrawn"
+	default:
+		return "In progress"
+	}
+}
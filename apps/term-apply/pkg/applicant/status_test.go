@@ -0,0 +1,122 @@
+package applicant
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStatusTimeline(t *testing.T) {
+	app := Application{
+		AppliedDate:      100,
+		RoleApplied:      "Software Engineer",
+		ResumeReviewDate: 200,
+		Interviews: map[string]Interview{
+			"10": {Date: 1000},
+			"2":  {Date: 300, Pass: true, Notes: []byte("great on-call stories")},
+			"1":  {Date: 250, Pass: false, Notes: []byte("nervous")},
+		},
+	}
+
+	status := statusFromApplication(app, time.Unix(500, 0))
+	var titles []string
+	for _, step := range status.Steps {
+		titles = append(titles, step.Title)
+	}
+	want := "Application received,Resume reviewed,Interview 1,Interview 2,Interview 10 scheduled"
+	if strings.Join(titles, ",") != want {
+		t.Fatalf("unexpected timeline %v", titles)
+	}
+	if status.Steps[4].Done {
+		t.Fatalf("future interviews should not be done")
+	}
+	if status.Closed || status.Next != "Interviews" {
+		t.Fatalf("application should still be open: %+v", status)
+	}
+
+	rendered := fmt.Sprintf("%+v", status)
+	for _, note := range []string{"great on-call stories", "nervous"} {
+		if strings.Contains(rendered, note) {
+			t.Fatalf("status must not reveal interview notes: %s", rendered)
+		}
+	}
+}
+
+func TestStatusRejection(t *testing.T) {
+	app := Application{AppliedDate: 100, Rejected: true, RejectedDate: 200}
+	status := statusFromApplication(app, time.Now())
+	if !status.Closed || status.Message != defaultRejectionMessage || status.Next != "" {
+		t.Fatalf("unexpected rejected status: %+v", status)
+	}
+
+	app.RejectedMsgOverride = []byte("We filled this role, please apply again next year!")
+	status = statusFromApplication(app, time.Now())
+	if status.Message != "We filled this role, please apply again next year!" {
+		t.Fatalf("expected the override message, got %q", status.Message)
+	}
+}
+
+func TestApplicationStatus(t *testing.T) {
+	store := NewMemoryStore()
+	am := newTestManager(t, store)
+
+	status, err := am.ApplicationStatus(context.Background(), "candy")
+	if err != nil || status != nil {
+		t.Fatalf("expected no status before applying, got %+v, %v", status, err)
+	}
+
+	addAndWait(t, am, "candy", "Candy Date", "candy@date.com", "software-engineer")
+	status, err = am.ApplicationStatus(context.Background(), "candy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status == nil || status.RoleApplied != "Software Engineer" || status.Next != "Resume review" {
+		t.Fatalf("unexpected status: %+v", status)
+	}
+}
+
+func TestApplicationHistory(t *testing.T) {
+	for name, store := range map[string]ApplicationStore{"memory": NewMemoryStore(), "bolt": newTestBoltStore(t)} {
+		am := newTestManager(t, store)
+		apps := []Application{
+			{AppliedDate: 100, Github: "candy", Email: "a@date.com", RoleApplied: "Software Engineer", Rejected: true, RejectedDate: 150},
+			{AppliedDate: 300, Github: "candy", Email: "c@date.com", RoleApplied: "Senior Software Engineer"},
+			{AppliedDate: 200, Github: "candy", Email: "b@date.com", RoleApplied: "Software Engineer", State: StateWithdrawn},
+			{AppliedDate: 200, Github: "candy", Email: "a@date.com", RoleApplied: "Software Engineer", State: StateAccepted},
+			{AppliedDate: 400, Github: "other", Email: "d@date.com", RoleApplied: "Software Engineer"},
+		}
+		for _, app := range apps {
+			if err := store.PutApplication(context.Background(), app); err != nil {
+				t.Fatalf("%s: %v", name, err)
+			}
+		}
+
+		var got []string
+		cursor, pages := "", 0
+		for {
+			page, next, err := am.ApplicationHistory(context.Background(), "candy", cursor, 3)
+			if err != nil {
+				t.Fatalf("%s: %v", name, err)
+			}
+			for _, app := range page {
+				got = append(got, fmt.Sprintf("%d %s", app.AppliedDate.Unix(), app.Outcome))
+			}
+			pages++
+			if next == "" {
+				break
+			}
+			cursor = next
+		}
+		want := []string{"300 In progress", "200 Withdrawn", "200 Offer accepted", "100 Not selected"}
+		if strings.Join(got, ",") != strings.Join(want, ",") || pages != 2 {
+			t.Fatalf("%s: unexpected history over %d pages: %v", name, pages, got)
+		}
+
+		page, next, err := am.ApplicationHistory(context.Background(), "nobody", "", 3)
+		if err != nil || len(page) != 0 || next != "" {
+			t.Fatalf("%s: expected no history, got %v %q %v", name, page, next, err)
+		}
+	}
+}
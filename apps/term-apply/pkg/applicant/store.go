@@ -0,0 +1,79 @@
+package applicant
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ApplicationStore persists applications for the ApplicantManager.
+//
+// Implementations must return an *emptyResultError from GetApplication when
+// the user has no applications, since the manager uses it to detect new
+// applicants. Every method should give up once ctx is done.
+type ApplicationStore interface {
+	// Returns the provided user's most recent application
+	GetApplication(ctx context.Context, user string) (Application, error)
+	// Writes a brand new application
+	PutApplication(ctx context.Context, app Application) error
+	// Updates an existing application in place, keyed by email and applied
+	// date. Returns an *ApplicationNotFoundError if it does not exist.
+	UpdateApplication(ctx context.Context, app Application) error
+	// Atomically replaces the application stored under prevEmail with app
+	RecreateApplication(ctx context.Context, app Application, prevEmail string) error
+	// Overwrites every field of an existing application, keyed by email and
+	// applied date. Used for staff edits.
+	SaveApplication(ctx context.Context, app Application) error
+	// Returns up to limit applications stored after cursor, and the cursor
+	// for the next page. An empty cursor starts at, or marks, the end.
+	ListApplications(ctx context.Context, cursor string, limit int) ([]Application, string, error)
+	// Returns up to limit of the provided user's applications, most recent
+	// first, stored after cursor, and the cursor for the next page
+	ListUserApplications(ctx context.Context, user string, cursor string, limit int) ([]Application, string, error)
+	// Counts one more application for each of roles. If a role has already
+	// received its MaxApplications, nothing is counted and a
+	// *RoleClosedError is returned for it.
+	CountRoleApplications(ctx context.Context, roles []Role) error
+	// Takes back the counts of an application that was not saved, for each
+	// of roleIDs at once
+	UncountRoleApplications(ctx context.Context, roleIDs []string) error
+	// Returns how many applications have been counted for each of roleIDs
+	RoleApplicationCounts(ctx context.Context, roleIDs []string) (map[string]int, error)
+}
+
+// checkRoleRoom returns a *RoleClosedError if role has a limit and count
+// applications have reached it
+func checkRoleRoom(role Role, count int) error {
+	if role.MaxApplications > 0 && count >= role.MaxApplications {
+		return &RoleClosedError{Role: role, Reason: reasonFull}
+	}
+	return nil
+}
+
+// applicationCursor identifies app for paging through ListApplications
+func applicationCursor(app Application) string {
+	return strconv.FormatInt(app.AppliedDate, 10) + ":" + app.Email
+}
+
+// newerApplication orders a user's applications most recent first, by
+// applied date and then email
+func newerApplication(app, other Application) bool {
+	if app.AppliedDate != other.AppliedDate {
+		return app.AppliedDate > other.AppliedDate
+	}
+	return app.Email > other.Email
+}
+
+func parseApplicationCursor(cursor string) (email string, appliedDate int64, err error) {
+	parts := strings.SplitN(cursor, ":", 2)
+	if len(parts) != 2 {
+		return "", 0, fmt.Errorf("invalid cursor %q", cursor)
+	}
+	email = parts[1]
+	appliedDate, err = strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid cursor %q", cursor)
+	}
+	return email, appliedDate, nil
+}
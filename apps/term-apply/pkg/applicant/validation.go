@@ -6,6 +6,15 @@ import (
 	"strings"
 )
 
+// InvalidInputError lists the fields of an application that are not valid
+type InvalidInputError struct {
+	Fields []string
+}
+
+func (err *InvalidInputError) Error() string {
+	return fmt.Sprintf("%d invalid inputs %v", len(err.Fields), strings.Join(err.Fields, ","))
+}
+
 func checkForInputErrors(name, email, role string) error {
 	var errs []string
 	if !isValidName(name) {
@@ -18,7 +27,7 @@ func checkForInputErrors(name, email, role string) error {
 		errs = append(errs, "role")
 	}
 	if len(errs) > 0 {
-		return fmt.Errorf("%d invalid inputs %v", len(errs), strings.Join(errs, ","))
+		return &InvalidInputError{Fields: errs}
 	}
 	return nil
 }
@@ -0,0 +1,60 @@
+package awsclient
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/aws/aws-sdk-go/service/ssm/ssmiface"
+)
+
+// Config controls how the shared AWS session is built
+type Config struct {
+	Region     string        // overrides the shared config region when set
+	MaxRetries int           // maximum retries for throttled or failed requests
+	Timeout    time.Duration // timeout for each HTTP request made to AWS
+	Endpoint   string        // custom endpoint (e.g. localstack) when set
+}
+
+// Clients holds AWS service clients built from a single session. Clients are
+// safe for concurrent use and should be created once and shared.
+type Clients struct {
+	S3       s3iface.S3API
+	DynamoDB dynamodbiface.DynamoDBAPI
+	SSM      ssmiface.SSMAPI
+}
+
+func New(c Config) (*Clients, error) {
+	awsConfig := aws.Config{
+		MaxRetries: aws.Int(c.MaxRetries),
+		HTTPClient: &http.Client{Timeout: c.Timeout},
+	}
+	if c.Region != "" {
+		awsConfig.Region = aws.String(c.Region)
+	}
+	if c.Endpoint != "" {
+		awsConfig.Endpoint = aws.String(c.Endpoint)
+		// Emulators rarely support virtual hosted bucket addressing
+		awsConfig.S3ForcePathStyle = aws.Bool(true)
+	}
+
+	sess, err := session.NewSessionWithOptions(session.Options{
+		Config:            awsConfig,
+		SharedConfigState: session.SharedConfigEnable,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &Clients{
+		S3:       s3.New(sess),
+		DynamoDB: dynamodb.New(sess),
+		SSM:      ssm.New(sess),
+	}, nil
+}
@@ -0,0 +1,38 @@
+package awsclient
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+func TestNewAppliesConfig(t *testing.T) {
+	clients, err := New(Config{
+		Region:     "us-west-2",
+		MaxRetries: 7,
+		Timeout:    5 * time.Second,
+		Endpoint:   "http://localhost:4566",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	svc, ok := clients.S3.(*s3.S3)
+	if !ok {
+		t.Fatalf("unexpected S3 client type %T", clients.S3)
+	}
+	if aws.StringValue(svc.Config.Region) != "us-west-2" {
+		t.Errorf("expected region to be applied, got %s", aws.StringValue(svc.Config.Region))
+	}
+	if aws.IntValue(svc.Config.MaxRetries) != 7 {
+		t.Errorf("expected retries to be applied, got %d", aws.IntValue(svc.Config.MaxRetries))
+	}
+	if svc.Config.HTTPClient.Timeout != 5*time.Second {
+		t.Errorf("expected timeout to be applied, got %s", svc.Config.HTTPClient.Timeout)
+	}
+	if svc.Endpoint != "http://localhost:4566" {
+		t.Errorf("expected endpoint to be applied, got %s", svc.Endpoint)
+	}
+}
@@ -0,0 +1,17 @@
+package blobstore
+
+import (
+	"context"
+	"time"
+)
+
+// BlobStore stores uploaded files, such as resumes, under string keys
+type BlobStore interface {
+	// Copies the local file at filename to key, replacing any existing blob
+	Put(ctx context.Context, filename, key string) error
+	// Reports whether a blob exists at key. An error means it could not be
+	// checked, not that the blob is missing.
+	Exists(ctx context.Context, key string) (bool, error)
+	// Returns when the blob at key was last written
+	LastModified(ctx context.Context, key string) (time.Time, error)
+}
@@ -0,0 +1,88 @@
+package blobstore
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// LocalStore is a BlobStore that keeps blobs in a directory on the local
+// filesystem. Keys are treated as paths relative to the root directory.
+type LocalStore struct {
+	root string
+}
+
+func NewLocalStore(root string) (*LocalStore, error) {
+	if err := os.MkdirAll(root, 0700); err != nil {
+		return nil, fmt.Errorf("cannot create blob directory %s: %w", root, err)
+	}
+	return &LocalStore{
+		root: filepath.Clean(root),
+	}, nil
+}
+
+func (l *LocalStore) Put(ctx context.Context, filename, key string) error {
+	src, err := os.Open(filename)
+	if err != nil {
+		return fmt.Errorf("failed to open file %q, %v", filename, err)
+	}
+	defer src.Close()
+
+	path := l.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+
+	// Write to a temporary file first so readers never see a partial blob
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, src); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	// Abandon the upload rather than replace the blob after cancellation
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return err
+	}
+
+	log.Printf("Stored %s at %s", filename, path)
+	return nil
+}
+
+func (l *LocalStore) Exists(ctx context.Context, key string) (bool, error) {
+	info, err := os.Stat(l.path(key))
+	if os.IsNotExist(err) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	return !info.IsDir(), nil
+}
+
+func (l *LocalStore) LastModified(ctx context.Context, key string) (time.Time, error) {
+	info, err := os.Stat(l.path(key))
+	if err != nil {
+		return time.Time{}, err
+	}
+	return info.ModTime().UTC(), nil
+}
+
+// path maps key inside the root directory. Cleaning the key as an absolute
+// path first strips any ".." components that would escape the root.
+func (l *LocalStore) path(key string) string {
+	return filepath.Join(l.root, filepath.Clean("/"+key))
+}
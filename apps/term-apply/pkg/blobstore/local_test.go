@@ -0,0 +1,77 @@
+package blobstore
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeTempFile(t *testing.T, contents string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "upload")
+	if err := os.WriteFile(path, []byte(contents), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLocalStorePut(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewLocalStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key := "/term-apply/dev/resumes/candy-resume.pdf"
+	if exists, _ := store.Exists(ctx, key); exists {
+		t.Fatalf("blob should not exist before upload")
+	}
+	if _, err := store.LastModified(ctx, key); err == nil {
+		t.Fatalf("expected an error for a missing blob")
+	}
+
+	if err := store.Put(ctx, writeTempFile(t, "first"), key); err != nil {
+		t.Fatal(err)
+	}
+	if exists, _ := store.Exists(ctx, key); !exists {
+		t.Fatalf("blob should exist after upload")
+	}
+	if _, err := store.LastModified(ctx, key); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := store.Put(ctx, writeTempFile(t, "second"), key); err != nil {
+		t.Fatal(err)
+	}
+	contents, _ := os.ReadFile(store.path(key))
+	if string(contents) != "second" {
+		t.Fatalf("expected replacement contents, got %q", contents)
+	}
+}
+
+func TestLocalStorePutCancelled(t *testing.T) {
+	store, _ := NewLocalStore(t.TempDir())
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	key := "candy-resume.pdf"
+	if err := store.Put(ctx, writeTempFile(t, "contents"), key); err == nil {
+		t.Fatalf("expected cancelled upload to fail")
+	}
+	if exists, _ := store.Exists(context.Background(), key); exists {
+		t.Fatalf("cancelled upload should not be stored")
+	}
+}
+
+func TestLocalStorePathStaysInRoot(t *testing.T) {
+	root := t.TempDir()
+	store, _ := NewLocalStore(root)
+
+	for _, key := range []string{"../../etc/passwd", "/a/../../b", "a/b/c"} {
+		if path := store.path(key); !strings.HasPrefix(path, root) {
+			t.Errorf("key %q escaped the root: %s", key, path)
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package blobstore
+
+import (
+	"context"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/nebulaworks/orion/apps/term-apply/pkg/s3file"
+)
+
+// S3Store is a BlobStore backed by a single S3 bucket
+type S3Store struct {
+	svc    s3iface.S3API
+	bucket string
+}
+
+func NewS3Store(svc s3iface.S3API, bucket string) *S3Store {
+	return &S3Store{
+		svc:    svc,
+		bucket: bucket,
+	}
+}
+
+func (s *S3Store) Put(ctx context.Context, filename, key string) error {
+	return s3file.CopyToS3(ctx, s.svc, s.bucket, filename, key)
+}
+
+func (s *S3Store) Exists(ctx context.Context, key string) (bool, error) {
+	return s3file.S3keyExists(ctx, s.svc, s.bucket, key)
+}
+
+func (s *S3Store) LastModified(ctx context.Context, key string) (time.Time, error) {
+	return s3file.S3keyLastModified(ctx, s.svc, s.bucket, key)
+}
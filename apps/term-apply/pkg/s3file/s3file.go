@@ -1,32 +1,28 @@
 package s3file
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"os"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/awserr"
-	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
 	"github.com/aws/aws-sdk-go/service/s3/s3manager"
 )
 
-func CopyFromS3(bucket, key, filename string) error {
+func CopyFromS3(ctx context.Context, svc s3iface.S3API, bucket, key, filename string) error {
 	file, err := os.Create(filename)
 	if err != nil {
 		return fmt.Errorf("cannot create %s", filename)
 	}
 	defer file.Close()
 
-	sess, err := session.NewSessionWithOptions(session.Options{
-		SharedConfigState: session.SharedConfigEnable,
-	})
-	if err != nil {
-		return err
-	}
-	downloader := s3manager.NewDownloader(sess)
-	numBytes, err := downloader.Download(file,
+	downloader := s3manager.NewDownloaderWithClient(svc)
+	numBytes, err := downloader.DownloadWithContext(ctx, file,
 		&s3.GetObjectInput{
 			Bucket: aws.String(bucket),
 			Key:    aws.String(key),
@@ -45,20 +41,14 @@ func CopyFromS3(bucket, key, filename string) error {
 	return nil
 }
 
-func CopyToS3(bucket, filename, key string) error {
-	sess, err := session.NewSessionWithOptions(session.Options{
-		SharedConfigState: session.SharedConfigEnable,
-	})
-	if err != nil {
-		return err
-	}
-	uploader := s3manager.NewUploader(sess)
+func CopyToS3(ctx context.Context, svc s3iface.S3API, bucket, filename, key string) error {
+	uploader := s3manager.NewUploaderWithClient(svc)
 	content, err := os.Open(filename)
 	if err != nil {
 		return fmt.Errorf("failed to open file %q, %v", filename, err)
 	}
 	defer content.Close()
-	result, err := uploader.Upload(&s3manager.UploadInput{
+	result, err := uploader.UploadWithContext(ctx, &s3manager.UploadInput{
 		Body:    content,
 		Bucket:  aws.String(bucket),
 		Key:     aws.String(key),
@@ -78,49 +68,31 @@ func CopyToS3(bucket, filename, key string) error {
 	return nil
 }
 
-func S3keyExists(bucket, key string) bool {
-	sess, err := session.NewSessionWithOptions(session.Options{
-		SharedConfigState: session.SharedConfigEnable,
-	})
-	if err != nil {
-		log.Printf("%v", err)
-		return false
-	}
-	svc := s3.New(sess)
-	_, err = svc.HeadObject(&s3.HeadObjectInput{
+// Reports whether an object exists at key. Errors other than the object
+// being missing are returned, since they say nothing about whether it exists.
+func S3keyExists(ctx context.Context, svc s3iface.S3API, bucket, key string) (bool, error) {
+	_, err := svc.HeadObjectWithContext(ctx, &s3.HeadObjectInput{
 		Bucket: aws.String(bucket),
 		Key:    aws.String(key),
 	})
 	if err != nil {
-		if aerr, ok := err.(awserr.Error); ok {
-			switch aerr.Code() {
-			case "NotFound":
-				return false
-			default:
-				log.Printf("%v", err)
-				return false
-			}
+		if aerr, ok := err.(awserr.Error); ok && aerr.Code() == "NotFound" {
+			return false, nil
 		}
-		log.Printf("%v", err)
-		return false
+		return false, err
 	}
-	return true
+	return true, nil
 }
 
-// Returns
-func S3keyLastModified(bucket, key string) string {
-	sess, err := session.NewSessionWithOptions(session.Options{
-		SharedConfigState: session.SharedConfigEnable,
-	})
-	if err != nil {
-		log.Printf("%v", err)
-	}
-	svc := s3.New(sess)
-
-	obj, err := svc.GetObject(&s3.GetObjectInput{
+// Returns the time the object at key was last modified
+func S3keyLastModified(ctx context.Context, svc s3iface.S3API, bucket, key string) (time.Time, error) {
+	obj, err := svc.HeadObjectWithContext(ctx, &s3.HeadObjectInput{
 		Bucket: aws.String(bucket),
 		Key:    aws.String(key),
 	})
+	if err != nil {
+		return time.Time{}, err
+	}
 
-	return obj.LastModified.Format("2006-01-02 15:04:05 UTC")
+	return aws.TimeValue(obj.LastModified), nil
 }
@@ -4,18 +4,49 @@ import (
 	"log"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 )
 
 type Config struct {
-	host            string
-	port            int
-	resumeTmpDir    string
-	s3Bucket        string
-	s3ResumePrefix  string
-	dynamodbTable   string
-	dynamodbIndex   string
-	ssmHostKeyParam string
-	hostKeyPath     string
+	host             string
+	port             int
+	resumeTmpDir     string
+	s3Bucket         string
+	s3ResumePrefix   string
+	resumeBackend    string
+	resumeDir        string
+	dynamodbTable    string
+	dynamodbIndex    string
+	storeBackend     string
+	boltPath         string
+	awsRegion        string
+	awsMaxRetries    int
+	awsTimeout       time.Duration
+	awsEndpoint      string
+	readTimeout      time.Duration
+	writeTimeout     time.Duration
+	uploadTimeout    time.Duration
+	journalPath      string
+	deadLetterPath   string
+	maxWriteAttempts int
+	retryBackoff     time.Duration
+	writeWorkers     int
+	writeQueueDepth  int
+	lockBackend      string
+	lockTTL          time.Duration
+	lockStatsEvery   time.Duration
+	staffUsers       []string
+	eventBackend     string
+	eventsTable      string
+	eventsBoltPath   string
+	workflowRules    string
+	workflowInterval time.Duration
+	workflowDryRun   bool
+	rolesPath        string
+	knockoutCooldown time.Duration
+	ssmHostKeyParam  string
+	hostKeyPath      string
 }
 
 func NewConfig() Config {
@@ -51,6 +82,18 @@ func NewConfig() Config {
 	}
 	log.Printf("TA_RESUME_PREFIX set to '%s'", s3ResumePrefix)
 
+	resumeBackend, ok := os.LookupEnv("TA_RESUME_BACKEND")
+	if !ok {
+		resumeBackend = "s3"
+	}
+	log.Printf("TA_RESUME_BACKEND set to '%s'", resumeBackend)
+
+	resumeDir, ok := os.LookupEnv("TA_RESUME_DIR")
+	if !ok {
+		resumeDir = "./resumes"
+	}
+	log.Printf("TA_RESUME_DIR set to '%s'", resumeDir)
+
 	dynamodbTable, ok := os.LookupEnv("TA_DYNAMODB_TABLE")
 	if !ok {
 		dynamodbTable = ""
@@ -63,6 +106,143 @@ func NewConfig() Config {
 	}
 	log.Printf("TA_DYNAMODB_GSI set to '%s'", dynamodbIndex)
 
+	storeBackend, ok := os.LookupEnv("TA_STORE_BACKEND")
+	if !ok {
+		storeBackend = "dynamodb"
+	}
+	log.Printf("TA_STORE_BACKEND set to '%s'", storeBackend)
+
+	boltPath, ok := os.LookupEnv("TA_BOLT_PATH")
+	if !ok {
+		boltPath = "./term-apply.db"
+	}
+	log.Printf("TA_BOLT_PATH set to '%s'", boltPath)
+
+	awsRegion, ok := os.LookupEnv("TA_AWS_REGION")
+	if !ok {
+		awsRegion = ""
+	}
+	log.Printf("TA_AWS_REGION set to '%s'", awsRegion)
+
+	awsMaxRetriesStr, ok := os.LookupEnv("TA_AWS_MAX_RETRIES")
+	awsMaxRetries, err := strconv.Atoi(awsMaxRetriesStr)
+	if !ok || err != nil {
+		awsMaxRetries = 3
+	}
+	log.Printf("TA_AWS_MAX_RETRIES set to '%d'", awsMaxRetries)
+
+	awsTimeout := durationFromEnv("TA_AWS_TIMEOUT", 30*time.Second)
+	log.Printf("TA_AWS_TIMEOUT set to '%s'", awsTimeout)
+
+	awsEndpoint, ok := os.LookupEnv("TA_AWS_ENDPOINT")
+	if !ok {
+		awsEndpoint = ""
+	}
+	log.Printf("TA_AWS_ENDPOINT set to '%s'", awsEndpoint)
+
+	readTimeout := durationFromEnv("TA_READ_TIMEOUT", 5*time.Second)
+	log.Printf("TA_READ_TIMEOUT set to '%s'", readTimeout)
+
+	writeTimeout := durationFromEnv("TA_WRITE_TIMEOUT", 10*time.Second)
+	log.Printf("TA_WRITE_TIMEOUT set to '%s'", writeTimeout)
+
+	uploadTimeout := durationFromEnv("TA_UPLOAD_TIMEOUT", 2*time.Minute)
+	log.Printf("TA_UPLOAD_TIMEOUT set to '%s'", uploadTimeout)
+
+	journalPath, ok := os.LookupEnv("TA_JOURNAL_PATH")
+	if !ok {
+		journalPath = "./term-apply-journal.jsonl"
+	}
+	log.Printf("TA_JOURNAL_PATH set to '%s'", journalPath)
+
+	deadLetterPath, ok := os.LookupEnv("TA_DEAD_LETTER_PATH")
+	if !ok {
+		deadLetterPath = "./term-apply-dead-letter.jsonl"
+	}
+	log.Printf("TA_DEAD_LETTER_PATH set to '%s'", deadLetterPath)
+
+	maxWriteAttemptsStr, ok := os.LookupEnv("TA_WRITE_MAX_ATTEMPTS")
+	maxWriteAttempts, err := strconv.Atoi(maxWriteAttemptsStr)
+	if !ok || err != nil {
+		maxWriteAttempts = 10
+	}
+	log.Printf("TA_WRITE_MAX_ATTEMPTS set to '%d'", maxWriteAttempts)
+
+	retryBackoff := durationFromEnv("TA_WRITE_RETRY_BACKOFF", time.Second)
+	log.Printf("TA_WRITE_RETRY_BACKOFF set to '%s'", retryBackoff)
+
+	writeWorkersStr, ok := os.LookupEnv("TA_WRITE_WORKERS")
+	writeWorkers, err := strconv.Atoi(writeWorkersStr)
+	if !ok || err != nil {
+		writeWorkers = 4
+	}
+	log.Printf("TA_WRITE_WORKERS set to '%d'", writeWorkers)
+
+	writeQueueDepthStr, ok := os.LookupEnv("TA_WRITE_QUEUE_DEPTH")
+	writeQueueDepth, err := strconv.Atoi(writeQueueDepthStr)
+	if !ok || err != nil {
+		writeQueueDepth = 16
+	}
+	log.Printf("TA_WRITE_QUEUE_DEPTH set to '%d'", writeQueueDepth)
+
+	lockBackend, ok := os.LookupEnv("TA_LOCK_BACKEND")
+	if !ok {
+		lockBackend = "local"
+	}
+	log.Printf("TA_LOCK_BACKEND set to '%s'", lockBackend)
+
+	lockTTL := durationFromEnv("TA_LOCK_TTL", 30*time.Second)
+	log.Printf("TA_LOCK_TTL set to '%s'", lockTTL)
+
+	lockStatsEvery := durationFromEnv("TA_LOCK_STATS_INTERVAL", 5*time.Minute)
+	log.Printf("TA_LOCK_STATS_INTERVAL set to '%s'", lockStatsEvery)
+
+	staffUsers := listFromEnv("TA_STAFF_USERS")
+	log.Printf("TA_STAFF_USERS set to '%s'", strings.Join(staffUsers, ","))
+
+	eventsTable, ok := os.LookupEnv("TA_EVENTS_TABLE")
+	if !ok {
+		eventsTable = ""
+	}
+	log.Printf("TA_EVENTS_TABLE set to '%s'", eventsTable)
+
+	eventBackend, ok := os.LookupEnv("TA_EVENT_BACKEND")
+	if !ok {
+		eventBackend = defaultEventBackend(storeBackend, eventsTable)
+	}
+	log.Printf("TA_EVENT_BACKEND set to '%s'", eventBackend)
+
+	eventsBoltPath, ok := os.LookupEnv("TA_EVENTS_BOLT_PATH")
+	if !ok {
+		eventsBoltPath = "./term-apply-events.db"
+	}
+	log.Printf("TA_EVENTS_BOLT_PATH set to '%s'", eventsBoltPath)
+
+	workflowRules, ok := os.LookupEnv("TA_WORKFLOW_RULES")
+	if !ok {
+		workflowRules = ""
+	}
+	log.Printf("TA_WORKFLOW_RULES set to '%s'", workflowRules)
+
+	workflowInterval := durationFromEnv("TA_WORKFLOW_INTERVAL", time.Hour)
+	log.Printf("TA_WORKFLOW_INTERVAL set to '%s'", workflowInterval)
+
+	workflowDryRunStr, ok := os.LookupEnv("TA_WORKFLOW_DRY_RUN")
+	workflowDryRun, err := strconv.ParseBool(workflowDryRunStr)
+	if !ok || err != nil {
+		workflowDryRun = false
+	}
+	log.Printf("TA_WORKFLOW_DRY_RUN set to '%t'", workflowDryRun)
+
+	rolesPath, ok := os.LookupEnv("TA_ROLES_PATH")
+	if !ok {
+		rolesPath = ""
+	}
+	log.Printf("TA_ROLES_PATH set to '%s'", rolesPath)
+
+	knockoutCooldown := durationFromEnv("TA_KNOCKOUT_COOLDOWN", 90*24*time.Hour)
+	log.Printf("TA_KNOCKOUT_COOLDOWN set to '%s'", knockoutCooldown)
+
 	ssmHostKeyParam, ok := os.LookupEnv("TA_SSM_HOST_KEY_PARAM")
 	if !ok {
 		ssmHostKeyParam = ""
@@ -75,14 +255,76 @@ func NewConfig() Config {
 	log.Printf("TA_HOST_KEY_PATH set to '%s'", hostKeyPath)
 
 	return Config{
-		host:            host,
-		port:            port,
-		resumeTmpDir:    resumeTmpDir,
-		s3Bucket:        s3Bucket,
-		s3ResumePrefix:  s3ResumePrefix,
-		dynamodbTable:   dynamodbTable,
-		dynamodbIndex:   dynamodbIndex,
-		ssmHostKeyParam: ssmHostKeyParam,
-		hostKeyPath:     hostKeyPath,
+		host:             host,
+		port:             port,
+		resumeTmpDir:     resumeTmpDir,
+		s3Bucket:         s3Bucket,
+		s3ResumePrefix:   s3ResumePrefix,
+		resumeBackend:    resumeBackend,
+		resumeDir:        resumeDir,
+		dynamodbTable:    dynamodbTable,
+		dynamodbIndex:    dynamodbIndex,
+		storeBackend:     storeBackend,
+		boltPath:         boltPath,
+		awsRegion:        awsRegion,
+		awsMaxRetries:    awsMaxRetries,
+		awsTimeout:       awsTimeout,
+		awsEndpoint:      awsEndpoint,
+		readTimeout:      readTimeout,
+		writeTimeout:     writeTimeout,
+		uploadTimeout:    uploadTimeout,
+		journalPath:      journalPath,
+		deadLetterPath:   deadLetterPath,
+		maxWriteAttempts: maxWriteAttempts,
+		retryBackoff:     retryBackoff,
+		writeWorkers:     writeWorkers,
+		writeQueueDepth:  writeQueueDepth,
+		lockBackend:      lockBackend,
+		lockTTL:          lockTTL,
+		lockStatsEvery:   lockStatsEvery,
+		staffUsers:       staffUsers,
+		eventBackend:     eventBackend,
+		eventsTable:      eventsTable,
+		eventsBoltPath:   eventsBoltPath,
+		workflowRules:    workflowRules,
+		workflowInterval: workflowInterval,
+		workflowDryRun:   workflowDryRun,
+		rolesPath:        rolesPath,
+		knockoutCooldown: knockoutCooldown,
+		ssmHostKeyParam:  ssmHostKeyParam,
+		hostKeyPath:      hostKeyPath,
+	}
+}
+
+// defaultEventBackend keeps events next to applications, except that events
+// are only kept in DynamoDB once a table has been given for them. Existing
+// deployments that predate the audit trail keep starting without one.
+func defaultEventBackend(storeBackend, eventsTable string) string {
+	if storeBackend == "dynamodb" && eventsTable == "" {
+		log.Printf("No TA_EVENTS_TABLE given, application events are kept in memory")
+		return "memory"
+	}
+	return storeBackend
+}
+
+// durationFromEnv parses a Go duration (ex: "30s") from the environment
+func durationFromEnv(name string, fallback time.Duration) time.Duration {
+	str, ok := os.LookupEnv(name)
+	duration, err := time.ParseDuration(str)
+	if !ok || err != nil {
+		return fallback
+	}
+	return duration
+}
+
+// listFromEnv parses a comma separated list from the environment, ignoring
+// empty entries
+func listFromEnv(name string) []string {
+	var list []string
+	for _, item := range strings.Split(os.Getenv(name), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
 	}
+	return list
 }
@@ -0,0 +1,42 @@
+package server
+
+import (
+	"os"
+	"testing"
+)
+
+// unsetEnv clears names for the rest of the test
+func unsetEnv(t *testing.T, names ...string) {
+	t.Helper()
+	for _, name := range names {
+		t.Setenv(name, "")
+		os.Unsetenv(name)
+	}
+}
+
+func TestConfigWithoutEventSettings(t *testing.T) {
+	unsetEnv(t, "TA_STORE_BACKEND", "TA_EVENT_BACKEND", "TA_EVENTS_TABLE")
+
+	c := NewConfig()
+	if c.eventBackend != "memory" {
+		t.Fatalf("expected events to be kept in memory without an events table, got %q", c.eventBackend)
+	}
+	if _, err := newEventStore(c, nil); err != nil {
+		t.Fatalf("expected a deployment without event settings to start: %v", err)
+	}
+}
+
+func TestDefaultEventBackend(t *testing.T) {
+	cases := []struct {
+		store, table, want string
+	}{
+		{"dynamodb", "", "memory"},
+		{"dynamodb", "term-apply-events", "dynamodb"},
+		{"bolt", "", "bolt"},
+	}
+	for _, c := range cases {
+		if got := defaultEventBackend(c.store, c.table); got != c.want {
+			t.Errorf("store %q with table %q: expected %q, got %q", c.store, c.table, c.want, got)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package server
 import (
 	"context"
 	"fmt"
+	"io"
 	"log"
 	"time"
 
@@ -13,26 +14,180 @@ import (
 	"github.com/gliderlabs/ssh"
 	"github.com/nebulaworks/orion/apps/term-apply/pkg/applicant"
 	"github.com/nebulaworks/orion/apps/term-apply/pkg/auth"
+	"github.com/nebulaworks/orion/apps/term-apply/pkg/awsclient"
+	"github.com/nebulaworks/orion/apps/term-apply/pkg/blobstore"
 	"github.com/nebulaworks/orion/apps/term-apply/pkg/ssmfile"
 	"github.com/nebulaworks/orion/apps/term-apply/pkg/transfer"
 	"github.com/nebulaworks/orion/apps/term-apply/pkg/ui"
+	"github.com/nebulaworks/orion/apps/term-apply/pkg/workflow"
 )
 
 type Server struct {
-	ws   *ssh.Server
-	host string
-	port int
+	ws       *ssh.Server
+	host     string
+	port     int
+	store    applicant.ApplicationStore
+	events   applicant.EventStore
+	am       *applicant.ApplicantManager
+	workflow *workflow.Engine // nil when no rules are configured
+
+	lockStatsEvery time.Duration // 0 disables logging lock stats
+	stop           chan struct{}
+}
+
+func newApplicationStore(c Config, clients *awsclient.Clients) (applicant.ApplicationStore, error) {
+	switch c.storeBackend {
+	case "dynamodb":
+		store := applicant.NewDynamoDBStore(clients.DynamoDB, c.dynamodbTable, c.dynamodbIndex)
+		ctx, cancel := context.WithTimeout(context.Background(), c.readTimeout)
+		defer cancel()
+		if err := store.CheckIndex(ctx); err != nil {
+			return nil, fmt.Errorf("checking DynamoDB index, see the README for migrating it: %w", err)
+		}
+		return store, nil
+	case "bolt":
+		return applicant.NewBoltStore(c.boltPath)
+	default:
+		return nil, fmt.Errorf("unknown store backend %q", c.storeBackend)
+	}
+}
+
+func newBlobStore(c Config, clients *awsclient.Clients) (blobstore.BlobStore, error) {
+	switch c.resumeBackend {
+	case "s3":
+		return blobstore.NewS3Store(clients.S3, c.s3Bucket), nil
+	case "local":
+		return blobstore.NewLocalStore(c.resumeDir)
+	default:
+		return nil, fmt.Errorf("unknown resume backend %q", c.resumeBackend)
+	}
+}
+
+func newLocker(c Config, clients *awsclient.Clients) (applicant.Locker, error) {
+	switch c.lockBackend {
+	case "local":
+		return applicant.NewLockVendor(), nil
+	case "dynamodb":
+		return applicant.NewDynamoDBLocker(clients.DynamoDB, c.dynamodbTable, c.lockTTL), nil
+	default:
+		return nil, fmt.Errorf("unknown lock backend %q", c.lockBackend)
+	}
+}
+
+func newEventStore(c Config, clients *awsclient.Clients) (applicant.EventStore, error) {
+	switch c.eventBackend {
+	case "dynamodb":
+		if c.eventsTable == "" {
+			return nil, fmt.Errorf("TA_EVENTS_TABLE is required for the dynamodb event backend")
+		}
+		return applicant.NewDynamoDBEventStore(clients.DynamoDB, c.eventsTable), nil
+	case "bolt":
+		return applicant.NewBoltEventStore(c.eventsBoltPath)
+	case "memory":
+		return applicant.NewMemoryEventStore(), nil
+	default:
+		return nil, fmt.Errorf("unknown event backend %q", c.eventBackend)
+	}
+}
+
+func newRoleCatalog(c Config) (*applicant.RoleCatalog, error) {
+	if c.rolesPath == "" {
+		log.Printf("No role catalog given, using the default roles")
+		return applicant.NewRoleCatalog(applicant.DefaultRoles)
+	}
+	return applicant.LoadRoleCatalog(c.rolesPath)
+}
+
+func newWorkflowEngine(c Config, am *applicant.ApplicantManager) (*workflow.Engine, error) {
+	if c.workflowRules == "" {
+		log.Printf("No workflow rules given, automation workflow is disabled")
+		return nil, nil
+	}
+	rules, err := workflow.LoadRules(c.workflowRules)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("Loaded %d workflow rules from %s", len(rules), c.workflowRules)
+	return workflow.NewEngine(am, rules, workflow.Config{
+		DryRun:   c.workflowDryRun,
+		Interval: c.workflowInterval,
+	})
 }
 
 func NewServer(c Config) (*Server, error) {
-	am, err := applicant.NewApplicantManager(c.s3Bucket, c.s3ResumePrefix, c.dynamodbTable, c.dynamodbIndex)
+	// AWS clients are built once and shared by every session
+	clients, err := awsclient.New(awsclient.Config{
+		Region:     c.awsRegion,
+		MaxRetries: c.awsMaxRetries,
+		Timeout:    c.awsTimeout,
+		Endpoint:   c.awsEndpoint,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	store, err := newApplicationStore(c, clients)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("Using %s application store", c.storeBackend)
+
+	blobs, err := newBlobStore(c, clients)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("Using %s resume backend", c.resumeBackend)
+
+	locks, err := newLocker(c, clients)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("Using %s applicant locks", c.lockBackend)
+
+	events, err := newEventStore(c, clients)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("Using %s event store", c.eventBackend)
+
+	roles, err := newRoleCatalog(c)
+	if err != nil {
+		return nil, err
+	}
+
+	am, err := applicant.NewApplicantManager(store, blobs, applicant.ManagerConfig{
+		ResumePrefix: c.s3ResumePrefix,
+		ReadTimeout:  c.readTimeout,
+		WriteTimeout: c.writeTimeout,
+
+		JournalPath:      c.journalPath,
+		DeadLetterPath:   c.deadLetterPath,
+		MaxWriteAttempts: c.maxWriteAttempts,
+		RetryBackoff:     c.retryBackoff,
+
+		WriteWorkers:    c.writeWorkers,
+		WriteQueueDepth: c.writeQueueDepth,
+
+		Locks:  locks,
+		Events: events,
+		Roles:  roles,
+
+		KnockoutCooldown: c.knockoutCooldown,
+	})
+	if err != nil {
+		return nil, err
+	}
+	tm := ui.NewTeaManager(am, c.staffUsers)
+
+	engine, err := newWorkflowEngine(c, am)
 	if err != nil {
 		return nil, err
 	}
-	tm := ui.NewTeaManager(am)
 
 	if c.ssmHostKeyParam != "" {
-		err = ssmfile.GetParamFromSSM(c.ssmHostKeyParam, c.hostKeyPath)
+		ctx, cancel := context.WithTimeout(context.Background(), c.readTimeout)
+		err = ssmfile.GetParamFromSSM(ctx, clients.SSM, c.ssmHostKeyParam, c.hostKeyPath)
+		cancel()
 		if err != nil {
 			return nil, err
 		}
@@ -49,7 +204,7 @@ func NewServer(c Config) (*Server, error) {
 		wish.WithMiddleware(
 			scp.Middleware(
 				transfer.NewNilCopyHandler(),
-				transfer.NewCopyFromClientHandler(c.resumeTmpDir, c.s3Bucket, c.s3ResumePrefix)),
+				transfer.NewCopyFromClientHandler(c.resumeTmpDir, blobs, am, c.s3ResumePrefix, c.uploadTimeout)),
 			bubbletea.Middleware(tm.TeaHandler),
 			logging.Middleware(),
 		),
@@ -58,9 +213,16 @@ func NewServer(c Config) (*Server, error) {
 		return &Server{}, err
 	}
 	return &Server{
-		ws:   ws,
-		host: c.host,
-		port: c.port,
+		ws:       ws,
+		host:     c.host,
+		port:     c.port,
+		store:    store,
+		events:   events,
+		am:       am,
+		workflow: engine,
+
+		lockStatsEvery: c.lockStatsEvery,
+		stop:           make(chan struct{}),
 	}, nil
 }
 
@@ -72,6 +234,28 @@ func (s *Server) Start() {
 			log.Fatal("exiting...")
 		}
 	}()
+	if s.workflow != nil {
+		s.workflow.Start()
+	}
+	if s.lockStatsEvery > 0 {
+		go s.logLockStats()
+	}
+}
+
+// logLockStats periodically logs how many applicant locks are live, so a
+// leak shows up in the logs long before it shows up in memory use
+func (s *Server) logLockStats() {
+	ticker := time.NewTicker(s.lockStatsEvery)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			stats := s.am.LockStats()
+			log.Printf("Applicant locks: %d live, %d references", stats.Live, stats.References)
+		case <-s.stop:
+			return
+		}
+	}
 }
 
 func (s *Server) Stop(ctx context.Context) {
@@ -80,4 +264,19 @@ func (s *Server) Stop(ctx context.Context) {
 		log.Printf("Server failed to stop %v", err)
 		log.Fatal("exiting...")
 	}
+	close(s.stop)
+	if s.workflow != nil {
+		s.workflow.Stop()
+	}
+	s.am.Close()
+	if closer, ok := s.store.(io.Closer); ok {
+		if err := closer.Close(); err != nil {
+			log.Printf("Failed to close application store %v", err)
+		}
+	}
+	if closer, ok := s.events.(io.Closer); ok {
+		if err := closer.Close(); err != nil {
+			log.Printf("Failed to close event store %v", err)
+		}
+	}
 }
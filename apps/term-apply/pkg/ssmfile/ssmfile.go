@@ -1,27 +1,19 @@
 package ssmfile
 
 import (
+	"context"
 	"log"
 	"os"
 	"path/filepath"
 
-	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/aws/aws-sdk-go/service/ssm/ssmiface"
 )
 
-func GetParamFromSSM(paramName, path string) error {
-	sess, err := session.NewSessionWithOptions(session.Options{
-		SharedConfigState: session.SharedConfigEnable,
-	})
-	if err != nil {
-		return err
-	}
-
-	ssm_client := ssm.New(sess)
-
+func GetParamFromSSM(ctx context.Context, ssm_client ssmiface.SSMAPI, paramName, path string) error {
 	decrypt := true
 	log.Printf("Getting parameter %s from ssm", paramName)
-	output, err := ssm_client.GetParameter(&ssm.GetParameterInput{
+	output, err := ssm_client.GetParameterWithContext(ctx, &ssm.GetParameterInput{
 		Name:           &paramName,
 		WithDecryption: &decrypt,
 	})
@@ -4,6 +4,7 @@ package transfer
 // https://pkg.go.dev/github.com/charmbracelet/wish/scp#CopyFromClientHandler
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"log"
@@ -15,16 +16,23 @@ import (
 	"github.com/charmbracelet/wish/scp"
 	"github.com/gabriel-vasile/mimetype"
 	"github.com/gliderlabs/ssh"
-	"github.com/nebulaworks/orion/apps/term-apply/pkg/s3file"
+	"github.com/nebulaworks/orion/apps/term-apply/pkg/blobstore"
 )
 
+// UploadRecorder is told about every resume that is stored
+type UploadRecorder interface {
+	RecordResumeUpload(github string)
+}
+
 type copyFromClientHandler struct {
-	root         string
-	bucket       string
-	resumePrefix string
+	root          string
+	blobs         blobstore.BlobStore
+	uploads       UploadRecorder
+	resumePrefix  string
+	uploadTimeout time.Duration
 }
 
-func NewCopyFromClientHandler(root, bucket, resumePrefix string) *copyFromClientHandler {
+func NewCopyFromClientHandler(root string, blobs blobstore.BlobStore, uploads UploadRecorder, resumePrefix string, uploadTimeout time.Duration) *copyFromClientHandler {
 	rootInfo, err := os.Stat(root)
 	if os.IsNotExist(err) {
 		log.Fatal(root + " doesn't exist")
@@ -33,9 +41,11 @@ func NewCopyFromClientHandler(root, bucket, resumePrefix string) *copyFromClient
 		log.Fatal(root + " is not a directory")
 	}
 	return &copyFromClientHandler{
-		root:         filepath.Clean(root),
-		bucket:       bucket,
-		resumePrefix: resumePrefix,
+		root:          filepath.Clean(root),
+		blobs:         blobs,
+		uploads:       uploads,
+		resumePrefix:  resumePrefix,
+		uploadTimeout: uploadTimeout,
 	}
 }
 
@@ -50,6 +60,10 @@ func (c *copyFromClientHandler) Mkdir(s ssh.Session, entry *scp.DirEntry) error
 
 func (c *copyFromClientHandler) Write(s ssh.Session, entry *scp.FileEntry) (int64, error) {
 
+	// Blob store calls are abandoned if the session drops mid-upload
+	ctx, cancel := c.uploadContext(s)
+	defer cancel()
+
 	user := s.User()
 	filename := fmt.Sprintf("%s-resume.pdf", user)
 	fileKey := fmt.Sprintf("%s/%s", c.resumePrefix, filename)
@@ -58,7 +72,9 @@ func (c *copyFromClientHandler) Write(s ssh.Session, entry *scp.FileEntry) (int6
 	// Check if resume has been uploaded
 	_, err := os.Stat(c.prefixed(filename))
 
-	if s3file.S3keyExists(c.bucket, fileKey) {
+	if exists, err := c.blobs.Exists(ctx, fileKey); err != nil {
+		log.Printf("Could not check for an existing resume %s for %s: %v", filename, user, err)
+	} else if exists {
 		log.Printf("Resume %s already exists: uploading replacement resume for %s.", filename, user)
 	} else {
 		log.Printf("Resume %s has not been uploaded: initial upload for %s.", filename, user)
@@ -78,7 +94,7 @@ func (c *copyFromClientHandler) Write(s ssh.Session, entry *scp.FileEntry) (int6
 	written, err := io.Copy(t, lr)
 	if err != nil {
 		log.Printf("error writing file %s, %v", filename, err)
-		return 0, fmt.Errorf("\nProvided file is too large. Maximum size is 10MB\n%s", getLastResumeStatus(c.bucket, fileKey, user))
+		return 0, fmt.Errorf("\nProvided file is too large. Maximum size is 10MB\n%s", getLastResumeStatus(ctx, c.blobs, fileKey, user))
 	}
 
 	// validate contents of uploaded file
@@ -90,7 +106,7 @@ func (c *copyFromClientHandler) Write(s ssh.Session, entry *scp.FileEntry) (int6
 	}
 	if !(mtype.String() == "application/pdf" || mtype.String() == "application/x-pdf") {
 		log.Printf("Provided file failed PDF validity check")
-		return 0, fmt.Errorf("\nProvided file failed PDF validity check\n%s", getLastResumeStatus(c.bucket, fileKey, user))
+		return 0, fmt.Errorf("\nProvided file failed PDF validity check\n%s", getLastResumeStatus(ctx, c.blobs, fileKey, user))
 	}
 	log.Printf("Provided file passed PDF vaildity check with type %s", mtype.String())
 
@@ -114,26 +130,44 @@ func (c *copyFromClientHandler) Write(s ssh.Session, entry *scp.FileEntry) (int6
 		log.Printf("failed to delete temp upload file")
 	}
 
-	// copy validated file to s3
+	// copy validated file to the blob store
 
-	if err := s3file.CopyToS3(c.bucket, localFile, fileKey); err != nil {
-		log.Printf("error writing to s3 %s, %s, %v", filename, fileKey, err)
+	if err := c.blobs.Put(ctx, localFile, fileKey); err != nil {
+		log.Printf("error writing to blob store %s, %s, %v", filename, fileKey, err)
 		return 0, fmt.Errorf("\nfailed to write file: %q", entry.Filepath)
 	}
+	c.uploads.RecordResumeUpload(user)
 
 	return written, c.chtimes(entry.Filepath, entry.Mtime, entry.Atime)
 }
 
-func getLastResumeStatus(bucket, fileKey string, user string) string {
+func getLastResumeStatus(ctx context.Context, blobs blobstore.BlobStore, fileKey string, user string) string {
 	var sts string
-	if s3file.S3keyExists(bucket, fileKey) {
-		sts = fmt.Sprintf("Last valid upload by user %s on %s", user, s3file.S3keyLastModified(bucket, fileKey))
+	exists, err := blobs.Exists(ctx, fileKey)
+	if err != nil {
+		log.Printf("error checking for %s: %v", fileKey, err)
+		return fmt.Sprintf("Could not check the last upload by user %s", user)
+	}
+	if exists {
+		lastModified, err := blobs.LastModified(ctx, fileKey)
+		if err != nil {
+			log.Printf("error getting last modified time of %s: %v", fileKey, err)
+			return fmt.Sprintf("Last valid upload by user %s on an unknown date", user)
+		}
+		sts = fmt.Sprintf("Last valid upload by user %s on %s", user, lastModified.UTC().Format("2006-01-02 15:04:05 UTC"))
 	} else {
 		sts = fmt.Sprintf("No valid file has been uploaded by user %s", user)
 	}
 	return sts
 }
 
+func (c *copyFromClientHandler) uploadContext(s ssh.Session) (context.Context, context.CancelFunc) {
+	if c.uploadTimeout <= 0 {
+		return context.WithCancel(s.Context())
+	}
+	return context.WithTimeout(s.Context(), c.uploadTimeout)
+}
+
 func (c *copyFromClientHandler) chtimes(path string, mtime, atime int64) error {
 	if mtime == 0 || atime == 0 {
 		return nil
@@ -1,6 +1,7 @@
 package ui
 
 import (
+	"log"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
@@ -13,11 +14,19 @@ func (m *Model) listenForActivity(sub chan responseMsg) tea.Cmd {
 		for {
 			// only send the message the message when we first
 			// find the resume
-			if m.appMgr.HasResume(m.userID) {
+			uploaded, err := m.appMgr.HasResume(m.ctx, m.userID)
+			if err != nil {
+				log.Printf("Could not check for a resume from %s: %v", m.userID, err)
+			} else if uploaded {
 				sub <- responseMsg{}
 				break
 			}
-			time.Sleep(time.Second * time.Duration(5))
+			// stop polling once the session has ended
+			select {
+			case <-m.ctx.Done():
+				return nil
+			case <-time.After(time.Second * time.Duration(5)):
+			}
 		}
 		return nil
 	}
@@ -0,0 +1,66 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	"github.com/nebulaworks/orion/apps/term-apply/pkg/applicant"
+)
+
+// Lines around the description viewport: its title and the help below it
+const descriptionChrome = 4
+
+// roleDescription is the job description being read
+type roleDescription struct {
+	role     applicant.Role
+	viewport viewport.Model
+}
+
+// focusedRole returns the role whose checkbox has focus
+func (m Model) focusedRole() (applicant.Role, bool) {
+	i := m.focusIndex - len(m.inputs)
+	if i < 0 || i >= len(m.roles) {
+		return applicant.Role{}, false
+	}
+	return m.roles[i], true
+}
+
+// openDescription shows the job description of role, sized to the terminal
+func (m *Model) openDescription(role applicant.Role) {
+	m.description = &roleDescription{role: role}
+	m.resizeDescription()
+}
+
+// resizeDescription fits the description to the terminal, wrapping it again
+// for the new width
+func (m *Model) resizeDescription() {
+	width, height := m.width, m.height
+	if width == 0 {
+		width, height = 80, 24
+	}
+	description := m.description.role.Description
+	if strings.TrimSpace(description) == "" {
+		description = "There is no description of this role yet."
+	}
+
+	if height <= descriptionChrome {
+		height = descriptionChrome + 1
+	}
+
+	offset := m.description.viewport.YOffset
+	m.description.viewport = viewport.New(width, height-descriptionChrome)
+	m.description.viewport.SetContent(renderMarkdown(description, width-2))
+	m.description.viewport.SetYOffset(offset)
+}
+
+func (m Model) descriptionView() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s\n\n", headingStyle.Render(" "+m.description.role.Title))
+	b.WriteString(m.description.viewport.View())
+	b.WriteString(helpStyle.Render(fmt.Sprintf(
+		"\n\n ↑/↓ pgup/pgdown to scroll • esc to go back • %3.f%%",
+		m.description.viewport.ScrollPercent()*100,
+	)))
+	return b.String()
+}
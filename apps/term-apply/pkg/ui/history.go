@@ -0,0 +1,79 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/nebulaworks/orion/apps/term-apply/pkg/applicant"
+)
+
+const historyPageSize = 5
+
+// applicationHistory is the page of the candidate's applications being shown
+type applicationHistory struct {
+	page    []applicant.PastApplication
+	cursors []string // the cursor each visited page was loaded from
+	next    string   // cursor of the next page, empty on the last page
+	loading bool
+	err     error
+}
+
+// historyMsg carries a page of the user's applications
+type historyMsg struct {
+	page   []applicant.PastApplication
+	cursor string
+	next   string
+	err    error
+}
+
+func (h applicationHistory) currentCursor() string {
+	return h.cursors[len(h.cursors)-1]
+}
+
+// A command that loads the page of the user's applications after cursor
+func (m *Model) loadHistory(cursor string) tea.Cmd {
+	ctx, appMgr, userID := m.ctx, m.appMgr, m.userID
+	return func() tea.Msg {
+		page, next, err := appMgr.ApplicationHistory(ctx, userID, cursor, historyPageSize)
+		return historyMsg{page: page, cursor: cursor, next: next, err: err}
+	}
+}
+
+func (h applicationHistory) update(msg historyMsg) applicationHistory {
+	h.loading = false
+	h.err = msg.err
+	if msg.err == nil {
+		h.page = msg.page
+		h.next = msg.next
+	}
+	return h
+}
+
+func historyView(h applicationHistory) string {
+	if h.err != nil {
+		return errorStyle.Render("\n Your past applications are unavailable right now.\n")
+	}
+	// A single application is already covered by its status
+	if len(h.cursors) == 1 && h.next == "" && len(h.page) <= 1 {
+		return ""
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "\n Your applications (page %d):\n", len(h.cursors))
+	for _, app := range h.page {
+		fmt.Fprintf(&b, "   %s  %-26s %s\n", app.AppliedDate.Format("2006-01-02"), app.RoleApplied, app.Outcome)
+	}
+
+	var keys []string
+	if len(h.cursors) > 1 {
+		keys = append(keys, "ctrl+p newer")
+	}
+	if h.next != "" {
+		keys = append(keys, "ctrl+n older")
+	}
+	if len(keys) > 0 {
+		b.WriteString(helpStyle.Render("   "+strings.Join(keys, " • ")) + "\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,200 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/nebulaworks/orion/apps/term-apply/pkg/applicant"
+)
+
+const (
+	interviewDate = iota
+	interviewInterviewer
+	interviewRating
+	interviewResult
+	interviewCompetencies
+	interviewNotes
+	interviewFields
+)
+
+// interviewForm edits the scorecard of one interview round
+type interviewForm struct {
+	round  string // empty when adding a new round
+	inputs []textinput.Model
+	focus  int
+	err    error // why the scorecard could not be saved
+}
+
+func newInterviewForm(round string, interview applicant.Interview) interviewForm {
+	f := interviewForm{
+		round:  round,
+		inputs: make([]textinput.Model, interviewFields),
+	}
+
+	placeholders := map[int]string{
+		interviewDate:         "Date (YYYY-MM-DD)",
+		interviewInterviewer:  "Interviewer",
+		interviewRating:       "Overall rating (1-5)",
+		interviewResult:       "Result (pass/fail)",
+		interviewCompetencies: "Competencies (name=rating, ...)",
+		interviewNotes:        "Notes",
+	}
+	values := map[int]string{
+		interviewDate:         time.Unix(interview.Date, 0).UTC().Format("2006-01-02"),
+		interviewInterviewer:  interview.Interviewer,
+		interviewRating:       strconv.Itoa(interview.Rating),
+		interviewResult:       passFail(interview.Pass),
+		interviewCompetencies: formatCompetencies(interview.Competencies),
+		interviewNotes:        string(interview.Notes),
+	}
+	if interview.Date == 0 {
+		values[interviewDate] = time.Now().UTC().Format("2006-01-02")
+		values[interviewRating] = ""
+		values[interviewResult] = ""
+	}
+
+	for i := range f.inputs {
+		t := textinput.New()
+		t.CursorStyle = cursorStyle
+		t.CharLimit = 64
+		t.Placeholder = placeholders[i]
+		t.SetValue(values[i])
+		if i == interviewNotes {
+			t.CharLimit = 1024
+		}
+		f.inputs[i] = t
+	}
+	f.setFocus(0)
+	return f
+}
+
+func (f *interviewForm) setFocus(focus int) tea.Cmd {
+	f.focus = focus
+	var cmd tea.Cmd
+	for i := range f.inputs {
+		if i == focus {
+			cmd = f.inputs[i].Focus()
+			f.inputs[i].PromptStyle = focusedStyle
+			f.inputs[i].TextStyle = focusedStyle
+			continue
+		}
+		f.inputs[i].Blur()
+		f.inputs[i].PromptStyle = noStyle
+		f.inputs[i].TextStyle = noStyle
+	}
+	return cmd
+}
+
+// update moves between fields and passes everything else to the focused
+// input
+func (f interviewForm) update(msg tea.KeyMsg) (interviewForm, tea.Cmd) {
+	switch msg.String() {
+	case "tab", "down":
+		return f, f.setFocus((f.focus + 1) % len(f.inputs))
+	case "shift+tab", "up":
+		return f, f.setFocus((f.focus + len(f.inputs) - 1) % len(f.inputs))
+	}
+	var cmd tea.Cmd
+	f.inputs[f.focus], cmd = f.inputs[f.focus].Update(msg)
+	return f, cmd
+}
+
+// interview parses the form into a scorecard
+func (f interviewForm) interview(interviewer string) (applicant.Interview, error) {
+	var interview applicant.Interview
+
+	date, err := time.Parse("2006-01-02", strings.TrimSpace(f.inputs[interviewDate].Value()))
+	if err != nil {
+		return interview, fmt.Errorf("date must look like 2006-01-02")
+	}
+	interview.Date = date.Unix()
+
+	interview.Interviewer = strings.TrimSpace(f.inputs[interviewInterviewer].Value())
+	if interview.Interviewer == "" {
+		interview.Interviewer = interviewer
+	}
+
+	interview.Rating, err = strconv.Atoi(strings.TrimSpace(f.inputs[interviewRating].Value()))
+	if err != nil {
+		return interview, fmt.Errorf("rating must be a number")
+	}
+
+	switch strings.ToLower(strings.TrimSpace(f.inputs[interviewResult].Value())) {
+	case "pass", "passed":
+		interview.Pass = true
+	case "fail", "failed":
+		interview.Pass = false
+	default:
+		return interview, fmt.Errorf("result must be pass or fail")
+	}
+
+	interview.Competencies, err = parseCompetencies(f.inputs[interviewCompetencies].Value())
+	if err != nil {
+		return interview, err
+	}
+
+	if notes := strings.TrimSpace(f.inputs[interviewNotes].Value()); notes != "" {
+		interview.Notes = []byte(notes)
+	}
+	return interview, nil
+}
+
+func (f interviewForm) view() string {
+	var b strings.Builder
+	if f.round == "" {
+		b.WriteString("\n New interview round\n\n")
+	} else {
+		fmt.Fprintf(&b, "\n Interview round %s\n\n", f.round)
+	}
+	for i := range f.inputs {
+		fmt.Fprintf(&b, " %s\n", f.inputs[i].View())
+	}
+	if f.err != nil {
+		b.WriteString(errorStyle.Render(fmt.Sprintf("\n %v\n", f.err)))
+	}
+	b.WriteString("\n")
+	b.WriteString(helpStyle.Render("tab next field • ctrl+s save • esc cancel"))
+	return b.String()
+}
+
+// parseCompetencies reads "name=rating, name=rating"
+func parseCompetencies(value string) (map[string]int, error) {
+	competencies := map[string]int{}
+	for _, entry := range strings.Split(value, ",") {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+		parts := strings.SplitN(entry, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("competencies must look like name=rating")
+		}
+		rating, err := strconv.Atoi(strings.TrimSpace(parts[1]))
+		if err != nil {
+			return nil, fmt.Errorf("competency %s must have a numeric rating", strings.TrimSpace(parts[0]))
+		}
+		competencies[strings.TrimSpace(parts[0])] = rating
+	}
+	if len(competencies) == 0 {
+		return nil, nil
+	}
+	return competencies, nil
+}
+
+func formatCompetencies(competencies map[string]int) string {
+	names := make([]string, 0, len(competencies))
+	for name := range competencies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	entries := make([]string, len(names))
+	for i, name := range names {
+		entries[i] = fmt.Sprintf("%s=%d", name, competencies[name])
+	}
+	return strings.Join(entries, ", ")
+}
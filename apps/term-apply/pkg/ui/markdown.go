@@ -0,0 +1,129 @@
+package ui
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/reflow/wordwrap"
+)
+
+var (
+	headingStyle = focusedStyle.Copy().Bold(true)
+	boldStyle    = lipgloss.NewStyle().Bold(true)
+	italicStyle  = lipgloss.NewStyle().Italic(true)
+	codeStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("#E8E8A6"))
+
+	headingPattern  = regexp.MustCompile(`^#{1,6}\s+(.*)$`)
+	listItemPattern = regexp.MustCompile(`^\s*([-*+]|\d+[.)])\s+(.*)$`)
+	linkPattern     = regexp.MustCompile(`\[([^\]]+)\]\(([^)\s]+)\)`)
+	codeSpanPattern = regexp.MustCompile("`([^`]+)`")
+	boldPattern     = regexp.MustCompile(`\*\*([^*]+)\*\*|__([^_]+)__`)
+	italicPattern   = regexp.MustCompile(`\*([^*\s][^*]*)\*|\b_([^_]+)_\b`)
+)
+
+// renderMarkdown renders the subset of markdown used in job descriptions -
+// headings, paragraphs, lists, code blocks and inline emphasis - wrapped to
+// width columns
+func renderMarkdown(src string, width int) string {
+	if width < 20 {
+		width = 20
+	}
+
+	var (
+		out       []string
+		paragraph []string // lines of the block being collected
+		prefix    string   // list marker of the block being collected
+		inCode    bool
+	)
+	blank := func() {
+		if len(out) > 0 && out[len(out)-1] != "" {
+			out = append(out, "")
+		}
+	}
+	flush := func() {
+		if len(paragraph) == 0 {
+			return
+		}
+		text := renderInline(strings.Join(paragraph, " "))
+		wrapped := strings.Split(wordwrap.String(text, width-len([]rune(prefix))), "\n")
+		for i, line := range wrapped {
+			if i == 0 {
+				out = append(out, prefix+line)
+			} else {
+				// Hang list items under their marker
+				out = append(out, strings.Repeat(" ", len([]rune(prefix)))+line)
+			}
+		}
+		paragraph, prefix = nil, ""
+	}
+
+	for _, line := range strings.Split(strings.ReplaceAll(src, "\r\n", "\n"), "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") {
+			flush()
+			blank()
+			inCode = !inCode
+			continue
+		}
+		if inCode {
+			// Code is kept as written
+			out = append(out, "  "+codeStyle.Render(line))
+			continue
+		}
+
+		switch {
+		case trimmed == "":
+			flush()
+			blank()
+		case headingPattern.MatchString(trimmed):
+			flush()
+			blank()
+			heading := headingPattern.FindStringSubmatch(trimmed)[1]
+			out = append(out, headingStyle.Render(wordwrap.String(renderInline(heading), width)), "")
+		case listItemPattern.MatchString(line):
+			flush()
+			item := listItemPattern.FindStringSubmatch(line)
+			marker := "•"
+			if item[1][0] >= '0' && item[1][0] <= '9' {
+				marker = item[1]
+			}
+			prefix = fmt.Sprintf("  %s ", marker)
+			paragraph = []string{item[2]}
+		default:
+			paragraph = append(paragraph, trimmed)
+		}
+	}
+	flush()
+
+	for len(out) > 0 && out[len(out)-1] == "" {
+		out = out[:len(out)-1]
+	}
+	return strings.Join(out, "\n")
+}
+
+// renderInline styles code spans, emphasis and links within a line. Code
+// spans are cut out first, so their contents are never read as markdown.
+func renderInline(text string) string {
+	var b strings.Builder
+	last := 0
+	for _, span := range codeSpanPattern.FindAllStringSubmatchIndex(text, -1) {
+		b.WriteString(renderEmphasis(text[last:span[0]]))
+		b.WriteString(codeStyle.Render(text[span[2]:span[3]]))
+		last = span[1]
+	}
+	b.WriteString(renderEmphasis(text[last:]))
+	return b.String()
+}
+
+// renderEmphasis styles emphasis and links in text without code spans
+func renderEmphasis(text string) string {
+	text = linkPattern.ReplaceAllString(text, "$1 ($2)")
+	text = boldPattern.ReplaceAllStringFunc(text, func(s string) string {
+		return boldStyle.Render(s[2 : len(s)-2])
+	})
+	return italicPattern.ReplaceAllStringFunc(text, func(s string) string {
+		return italicStyle.Render(s[1 : len(s)-1])
+	})
+}
@@ -0,0 +1,101 @@
+package ui
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+var ansiPattern = regexp.MustCompile("\x1b\\[[0-9;]*m")
+
+// plain strips styling, leaving the text a terminal would show
+func plain(s string) string {
+	return ansiPattern.ReplaceAllString(s, "")
+}
+
+func TestRenderInline(t *testing.T) {
+	cases := []struct {
+		name, src, want string
+		styles          []string // escape sequences the output must contain
+	}{
+		{"bold", "a **strong** b", "a strong b", []string{"\x1b[1m"}},
+		{"bold underscores", "a __strong__ b", "a strong b", []string{"\x1b[1m"}},
+		{"italic", "a *soft* b", "a soft b", []string{"\x1b[3m"}},
+		{"italic underscores", "a _soft_ b", "a soft b", []string{"\x1b[3m"}},
+		{"code", "run `make test` now", "run make test now", nil},
+		{"emphasis in code", "Use `a*b` and *c* here", "Use a*b and c here", []string{"\x1b[3m"}},
+		{"bold in code", "`**kept**` as is", "**kept** as is", nil},
+		{"link", "see [the docs](https://example.com)", "see the docs (https://example.com)", nil},
+		{"link in code", "`[a](b)`", "[a](b)", nil},
+		{"snake case", "use snake_case_names", "use snake_case_names", nil},
+	}
+	for _, c := range cases {
+		got := renderInline(c.src)
+		if plain(got) != c.want {
+			t.Errorf("%s: expected %q, got %q", c.name, c.want, plain(got))
+		}
+		for _, style := range c.styles {
+			if !strings.Contains(got, style) {
+				t.Errorf("%s: expected %q to be styled with %q", c.name, got, style)
+			}
+		}
+	}
+	if got := renderInline("Use `a*b` and `c*d`"); strings.Contains(got, "\x1b[3m") {
+		t.Errorf("code spans should not be italicized: %q", got)
+	}
+}
+
+func TestRenderMarkdown(t *testing.T) {
+	cases := []struct {
+		name, src, want string
+		width           int
+	}{
+		{
+			name:  "heading",
+			src:   "# About the role\nWe build things.",
+			want:  "About the role\n\nWe build things.",
+			width: 40,
+		},
+		{
+			name:  "paragraphs",
+			src:   "First line\ncontinues here.\n\n\nSecond paragraph.",
+			want:  "First line continues here.\n\nSecond paragraph.",
+			width: 40,
+		},
+		{
+			name:  "lists",
+			src:   "- Go\n* Terraform\n1. Apply\n2) Interview",
+			want:  "  • Go\n  • Terraform\n  1. Apply\n  2) Interview",
+			width: 40,
+		},
+		{
+			name:  "wrapping",
+			src:   "one two three four five six seven eight nine ten eleven",
+			want:  "one two three four five six\nseven eight nine ten eleven",
+			width: 28,
+		},
+		{
+			name:  "wrapped list item hangs under its marker",
+			src:   "- one two three four five six seven eight nine",
+			want:  "  • one two three four five six\n    seven eight nine",
+			width: 32,
+		},
+		{
+			name:  "code block",
+			src:   "Run:\n```\nmake  *test*\n```\nDone.",
+			want:  "Run:\n\n  make  *test*\n\nDone.",
+			width: 40,
+		},
+		{
+			name:  "narrow widths wrap at 20 columns",
+			src:   "one two three four five",
+			want:  "one two three four\nfive",
+			width: 5,
+		},
+	}
+	for _, c := range cases {
+		if got := plain(renderMarkdown(c.src, c.width)); got != c.want {
+			t.Errorf("%s: expected\n%q\ngot\n%q", c.name, c.want, got)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package ui
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
@@ -10,31 +11,46 @@ import (
 )
 
 type Model struct {
+	ctx        context.Context // scoped to the ssh session
 	focusIndex int
-	choice     int
-	checkBoxes []string
+	chosen     []bool            // whether each of roles is checked
+	roles      []applicant.Role  // open roles, in the order they are listed
+	rolesReady bool              // roles have been loaded
+	closed     map[string]string // role ID to why it closed after the form was opened
+	answers    map[string]int    // question ID to the index of the chosen option
 	inputs     []textinput.Model
 	cursorMode textinput.CursorMode
 	Submitted  bool
-	sub        chan responseMsg // where we'll receive activity notifications
-	response   string
-	appMgr     *applicant.ApplicantManager
-	userID     string
+	saving     bool              // a submission is waiting to be persisted
+	receipt    applicant.Receipt // confirmation of the last submission
+	submitErr  error             // why the last submission failed
+	status     *applicant.Status // latest application status, nil if none
+	statusErr  error             // why the status could not be loaded
+	history    applicationHistory
+	// withdrawal of the latest application
+	confirmWithdraw bool             // waiting for the user to confirm
+	withdrawing     bool             // the withdrawal is being saved
+	withdrawErr     error            // why the last withdrawal failed
+	description     *roleDescription // job description being read, nil if none
+	width, height   int              // size of the terminal
+	sub             chan responseMsg // where we'll receive activity notifications
+	response        string
+	appMgr          *applicant.ApplicantManager
+	userID          string
 }
 
-func InitialModel(am *applicant.ApplicantManager, user string) Model {
+func InitialModel(ctx context.Context, am *applicant.ApplicantManager, user string) Model {
 	m := Model{
-		inputs:     make([]textinput.Model, 2),
-		checkBoxes: make([]string, 2),
-		sub:        make(chan responseMsg),
-		appMgr:     am,
-		userID:     user,
-		response:   "not found",
+		ctx:      ctx,
+		inputs:   make([]textinput.Model, 2),
+		answers:  map[string]int{},
+		closed:   map[string]string{},
+		sub:      make(chan responseMsg),
+		appMgr:   am,
+		userID:   user,
+		response: "not found",
+		history:  applicationHistory{cursors: []string{""}, loading: true},
 	}
-
-	m.checkBoxes[0] = "Senior Software Engineer"
-	m.checkBoxes[1] = "Software Engineer"
-
 	var t textinput.Model
 	for i := range m.inputs {
 		t = textinput.New()
@@ -60,8 +76,12 @@ func InitialModel(am *applicant.ApplicantManager, user string) Model {
 func (m Model) Init() tea.Cmd {
 	return tea.Batch(
 		textinput.Blink,
+		m.loadRoles(),
+		m.loadStatus(),
+		m.loadHistory(""),
 		m.listenForActivity(m.sub), // generate activity
 		waitForActivity(m.sub),     // wait for activity
+		checkRolesLater(),
 	)
 
 }
@@ -71,12 +91,109 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case responseMsg:
 		m.response = "received, thank you"
 		return m, waitForActivity(m.sub) // wait for next event
+	case submitResultMsg:
+		m.saving = false
+		m.receipt = msg.receipt
+		m.submitErr = msg.err
+		m.Submitted = msg.err == nil
+		if m.Submitted {
+			// A submission may have started a new application
+			m.history = applicationHistory{cursors: []string{""}, loading: true}
+			return m, tea.Batch(m.loadStatus(), m.loadHistory(""))
+		}
+		m.markClosedRole(msg.err)
+		return m, nil
+	case rolesMsg:
+		m.setRoles(msg.roles)
+		return m, nil
+	case rolesTickMsg:
+		// Rendering again marks roles that closed on schedule
+		return m, checkRolesLater()
+	case statusMsg:
+		m.status = msg.status
+		m.statusErr = msg.err
+		return m, nil
+	case withdrawResultMsg:
+		m.withdrawing = false
+		m.withdrawErr = msg.err
+		if msg.err == nil {
+			// The next submission starts a new application
+			m.Submitted = false
+			m.history = applicationHistory{cursors: []string{""}, loading: true}
+			return m, tea.Batch(m.loadStatus(), m.loadHistory(""))
+		}
+		return m, nil
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+		if m.description != nil {
+			m.resizeDescription()
+		}
+		return m, nil
+	case historyMsg:
+		// Ignore pages that are no longer wanted
+		if msg.cursor == m.history.currentCursor() {
+			m.history = m.history.update(msg)
+		}
+		return m, nil
 	case tea.KeyMsg:
+		if m.confirmWithdraw {
+			// Any key other than y backs out of withdrawing
+			m.confirmWithdraw = false
+			if msg.String() == "y" {
+				m.withdrawing = true
+				m.withdrawErr = nil
+				return m, m.withdrawApplication()
+			}
+			return m, nil
+		}
+
+		if m.description != nil {
+			switch msg.String() {
+			case "ctrl+c":
+				return m, tea.Quit
+			case "esc", "q", "?":
+				m.description = nil
+				return m, nil
+			}
+			var cmd tea.Cmd
+			m.description.viewport, cmd = m.description.viewport.Update(msg)
+			return m, cmd
+		}
+
 		switch msg.String() {
 
 		case "ctrl+c", "esc":
 			return m, tea.Quit
 
+		// Read the job description of the focused role
+		case "?":
+			if role, ok := m.focusedRole(); ok {
+				m.openDescription(role)
+				return m, nil
+			}
+
+		case "ctrl+x":
+			if m.canWithdraw() {
+				m.confirmWithdraw = true
+			}
+			return m, nil
+
+		// Page through past applications
+		case "ctrl+n":
+			if m.history.next != "" && !m.history.loading {
+				m.history.cursors = append(m.history.cursors, m.history.next)
+				m.history.loading = true
+				return m, m.loadHistory(m.history.next)
+			}
+			return m, nil
+		case "ctrl+p":
+			if len(m.history.cursors) > 1 && !m.history.loading {
+				m.history.cursors = m.history.cursors[:len(m.history.cursors)-1]
+				m.history.loading = true
+				return m, m.loadHistory(m.history.currentCursor())
+			}
+			return m, nil
+
 		// Change cursor mode
 		case "ctrl+r":
 			m.cursorMode++
@@ -89,25 +206,40 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 			return m, tea.Batch(cmds...)
 
+		// Answer the focused question
+		case "left", "right":
+			if _, ok := m.focusedQuestion(); ok {
+				if msg.String() == "left" {
+					m.changeAnswer(-1)
+				} else {
+					m.changeAnswer(1)
+				}
+				return m, nil
+			}
+
 		// Set focus to next input
 		case "tab", "shift+tab", "enter", "up", "down":
 			s := msg.String()
 
 			// Did the user press enter while the submit button was focused?
-			// If so, exit.
-			if s == "enter" && m.focusIndex == (len(m.inputs)+len(m.checkBoxes)) {
-				if err := m.appMgr.AddApplicant(
-					m.userID,
-					m.inputs[0].Value(),
-					m.inputs[1].Value(),
-					m.choice,
-				); err == nil {
-					m.Submitted = true
-				} else {
+			// If so, save the application in the background.
+			var submitCmd tea.Cmd
+			if s == "enter" && m.focusIndex == m.submitIndex() {
+				if len(m.chosenRoles()) == 0 {
+					m.submitErr = errNoRole
+				} else if _, ok := m.answerMap(); !ok {
+					m.submitErr = errUnanswered
+				} else if !m.saving {
+					m.saving = true
 					m.Submitted = false
+					m.submitErr = nil
+					submitCmd = m.submitApplication()
+				}
+			} else if role, ok := m.focusedRole(); s == "enter" && ok {
+				// Closed roles can be unchecked but not checked
+				if m.chosen[m.focusIndex-2] || m.closedReason(role) == "" {
+					m.chosen[m.focusIndex-2] = !m.chosen[m.focusIndex-2]
 				}
-			} else if s == "enter" && m.focusIndex >= 2 {
-				m.choice = m.focusIndex - 2
 				m.focusIndex = m.focusIndex - 1
 			}
 
@@ -118,15 +250,15 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.focusIndex++
 			}
 
-			if m.focusIndex > (len(m.inputs) + len(m.checkBoxes)) {
+			if m.focusIndex > m.submitIndex() {
 				// if we exceed the focus index just keep us where we were
 				m.focusIndex = m.focusIndex - 1
 			} else if m.focusIndex < 0 {
-				m.focusIndex = len(m.inputs) + len(m.checkBoxes)
+				m.focusIndex = m.submitIndex()
 			}
 
-			cmds := make([]tea.Cmd, (len(m.inputs) + len(m.checkBoxes)))
-			for i := 0; i <= (len(m.inputs)+len(m.checkBoxes))-1; i++ {
+			cmds := make([]tea.Cmd, m.submitIndex())
+			for i := 0; i <= m.submitIndex()-1; i++ {
 				if i == m.focusIndex && i < 2 {
 					// if re-editing info
 					m.Submitted = false
@@ -145,6 +277,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 			}
 
+			cmds = append(cmds, submitCmd)
 			return m, tea.Batch(cmds...)
 		}
 	}
@@ -168,6 +301,10 @@ func (m *Model) updateInputs(msg tea.Msg) tea.Cmd {
 }
 
 func (m Model) View() string {
+	if m.description != nil {
+		return m.descriptionView()
+	}
+
 	var b strings.Builder
 
 	for i := range m.inputs {
@@ -180,34 +317,52 @@ func (m Model) View() string {
 	b.WriteRune('\n')
 	b.WriteRune('\n')
 	var focus = false
-	for i := range m.checkBoxes {
+	if !m.rolesReady {
+		b.WriteString(" Loading open roles...\n")
+	} else if len(m.roles) == 0 {
+		b.WriteString(" There are no open roles right now, please check back later.\n")
+	}
+	for i := range m.roles {
 		if m.focusIndex-2 == i {
 			focus = true
 		} else {
 			focus = false
 		}
 
-		if m.choice == i {
-			b.WriteString(checkbox(m.checkBoxes[i], true, focus))
-		} else {
-			b.WriteString(checkbox(m.checkBoxes[i], false, focus))
-		}
+		b.WriteString(checkbox(roleLabel(m.roles[i], m.closedReason(m.roles[i]) != ""), m.chosen[i], focus))
 
-		if i < len(m.inputs)-1 {
+		if i < len(m.roles)-1 {
 			b.WriteRune('\n')
 		}
 	}
 
+	if _, ok := m.focusedRole(); ok {
+		b.WriteString(helpStyle.Render("\n enter to check or uncheck a role • ? to read the job description"))
+	}
+	b.WriteString(m.questionsView())
+
 	button := &blurredButton
-	if m.focusIndex == len(m.inputs)+len(m.checkBoxes) {
+	if m.focusIndex == m.submitIndex() {
 		button = &focusedButton
 	}
 	fmt.Fprintf(&b, "\n\n%s\n\n", *button)
 
-	if m.Submitted {
-		b.WriteString(fmt.Sprintf("\n %s Thank you for applying to Nebulaworks!\n", m.inputs[0].Value()))
-		b.WriteString(fmt.Sprintf(" We will follow up with you via your email: %s\n", m.inputs[1].Value()))
+	if m.saving {
+		b.WriteString("\n Saving your application...\n")
+	} else if m.Submitted {
+		b.WriteString(fmt.Sprintf("\n %s Thank you for applying to Nebulaworks!\n", m.receipt.Name))
+		b.WriteString(fmt.Sprintf(" We will follow up with you via your email: %s\n", m.receipt.Email))
+		b.WriteString(fmt.Sprintf(
+			" Application for %s received on %s\n",
+			m.receipt.RoleApplied,
+			m.receipt.AppliedDate.Format("2006-01-02"),
+		))
+	} else if m.submitErr != nil {
+		b.WriteString(submitErrorView(m.submitErr))
 	}
+	b.WriteString(statusView(m.status, m.statusErr))
+	b.WriteString(m.withdrawView())
+	b.WriteString(historyView(m.history))
 	b.WriteString(fmt.Sprintf("\n Resume status: %s \n\n", m.response))
 	b.WriteString(helpStyle.Render("ctrl+c to exit"))
 	b.WriteString("\n\n")
@@ -0,0 +1,124 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/nebulaworks/orion/apps/term-apply/pkg/applicant"
+)
+
+var (
+	errUnanswered = errors.New("please answer every question")
+	errNoRole     = errors.New("please choose at least one role")
+)
+
+// chosenRoles returns the roles the user has checked, in listing order
+func (m Model) chosenRoles() []applicant.Role {
+	var roles []applicant.Role
+	for i, role := range m.roles {
+		if m.chosen[i] {
+			roles = append(roles, role)
+		}
+	}
+	return roles
+}
+
+// questions returns the screening questions of the chosen roles. Roles
+// asking the same question share one answer.
+func (m Model) questions() []applicant.Question {
+	var questions []applicant.Question
+	asked := map[string]bool{}
+	for _, role := range m.chosenRoles() {
+		for _, q := range role.Questions {
+			if !asked[q.ID] {
+				asked[q.ID] = true
+				questions = append(questions, q)
+			}
+		}
+	}
+	return questions
+}
+
+// submitIndex is the focus index of the submit button, after the inputs,
+// role checkboxes and questions
+func (m Model) submitIndex() int {
+	return len(m.inputs) + len(m.roles) + len(m.questions())
+}
+
+// focusedQuestion returns the index of the question with focus
+func (m Model) focusedQuestion() (int, bool) {
+	i := m.focusIndex - len(m.inputs) - len(m.roles)
+	return i, i >= 0 && i < len(m.questions())
+}
+
+// changeAnswer moves the answer to the focused question by step options
+func (m *Model) changeAnswer(step int) {
+	i, ok := m.focusedQuestion()
+	if !ok {
+		return
+	}
+	q := m.questions()[i]
+	options := len(q.Options())
+	answer, ok := m.answers[q.ID]
+	if !ok && step < 0 {
+		m.answers[q.ID] = options - 1
+		return
+	}
+	if !ok {
+		answer = -1
+	}
+	m.answers[q.ID] = (answer + step + options) % options
+}
+
+// answerMap returns the answers to submit, and false if a question has not
+// been answered
+func (m Model) answerMap() (map[string]string, bool) {
+	questions := m.questions()
+	if len(questions) == 0 {
+		return nil, true
+	}
+	answers := make(map[string]string, len(questions))
+	for _, q := range questions {
+		answer, ok := m.answers[q.ID]
+		if !ok {
+			return nil, false
+		}
+		answers[q.ID] = q.Options()[answer]
+	}
+	return answers, true
+}
+
+func (m Model) questionsView() string {
+	questions := m.questions()
+	if len(questions) == 0 {
+		return ""
+	}
+
+	focused, _ := m.focusedQuestion()
+	var b strings.Builder
+	b.WriteString("\n\n")
+	for i, q := range questions {
+		options := make([]string, len(q.Options()))
+		answer, answered := m.answers[q.ID]
+		for j, option := range q.Options() {
+			if answered && answer == j {
+				options[j] = "(•) " + option
+			} else {
+				options[j] = "( ) " + option
+			}
+		}
+		line := fmt.Sprintf("%s\n    %s", q.Text, strings.Join(options, "  "))
+		if i == focused {
+			line = focusedStyle.Render(line)
+		}
+		b.WriteString(line)
+		if i < len(questions)-1 {
+			b.WriteRune('\n')
+		}
+	}
+	if _, ok := m.focusedQuestion(); ok {
+		b.WriteString(helpStyle.Render("\n ←/→ to choose an answer"))
+	}
+	return b.String()
+}
@@ -0,0 +1,82 @@
+package ui
+
+import (
+	"errors"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/nebulaworks/orion/apps/term-apply/pkg/applicant"
+)
+
+// How often the form checks whether roles have closed while it is open
+const roleCheckInterval = time.Minute
+
+// rolesMsg carries the roles open when the form was opened
+type rolesMsg struct {
+	roles []applicant.Role
+}
+
+// A command that loads the open roles, which counts applications for roles
+// with a limit
+func (m *Model) loadRoles() tea.Cmd {
+	ctx, appMgr := m.ctx, m.appMgr
+	return func() tea.Msg {
+		return rolesMsg{roles: appMgr.OpenRoles(ctx)}
+	}
+}
+
+// setRoles lists roles on the form, checking the first. The submit button
+// stays focused if it was.
+func (m *Model) setRoles(roles []applicant.Role) {
+	onSubmit := m.focusIndex == m.submitIndex()
+	m.roles, m.rolesReady = roles, true
+	m.chosen = make([]bool, len(roles))
+	if len(m.chosen) > 0 {
+		m.chosen[0] = true
+	}
+	if onSubmit {
+		m.focusIndex = m.submitIndex()
+	}
+}
+
+// rolesTickMsg asks the form to check its roles again
+type rolesTickMsg struct{}
+
+// A command that waits before the roles are checked again
+func checkRolesLater() tea.Cmd {
+	return tea.Tick(roleCheckInterval, func(time.Time) tea.Msg {
+		return rolesTickMsg{}
+	})
+}
+
+// closedReason explains why role has stopped accepting applications since
+// the form was opened, or returns "" if it has not
+func (m Model) closedReason(role applicant.Role) string {
+	if reason, ok := m.closed[role.ID]; ok {
+		return reason
+	}
+	return role.ClosedReason(time.Now())
+}
+
+// markClosedRole remembers and unchecks the role a submission found closed.
+// Roles that close on schedule stay checked, since the candidate may already
+// have applied for them.
+func (m *Model) markClosedRole(err error) {
+	var closed *applicant.RoleClosedError
+	if !errors.As(err, &closed) {
+		return
+	}
+	m.closed[closed.Role.ID] = closed.Error()
+	for i, role := range m.roles {
+		if role.ID == closed.Role.ID {
+			m.chosen[i] = false
+		}
+	}
+}
+
+func roleLabel(role applicant.Role, closed bool) string {
+	if closed {
+		return role.Title + " (closed)"
+	}
+	return role.Title
+}
@@ -0,0 +1,42 @@
+package ui
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/nebulaworks/orion/apps/term-apply/pkg/applicant"
+)
+
+func TestRolesLoadAfterFormOpens(t *testing.T) {
+	// Opening the form must not read roles, which may count applications
+	m := InitialModel(context.Background(), nil, "candy")
+	if m.rolesReady || !strings.Contains(m.View(), "Loading open roles") {
+		t.Fatalf("expected the form to wait for its roles")
+	}
+
+	// Tab past the name and email to the submit button
+	for i := 0; i < 2; i++ {
+		updated, _ := m.Update(keyMsg("tab"))
+		m = updated.(Model)
+	}
+	if m.focusIndex != m.submitIndex() {
+		t.Fatalf("expected the submit button to be focused, got %d", m.focusIndex)
+	}
+
+	roles := []applicant.Role{
+		{ID: "swe", Title: "Software Engineer", Open: true},
+		{ID: "sre", Title: "Site Reliability Engineer", Open: true},
+	}
+	updated, _ := m.Update(rolesMsg{roles: roles})
+	m = updated.(Model)
+	if len(m.chosen) != 2 || !m.chosen[0] || m.chosen[1] {
+		t.Fatalf("expected the first role to be checked: %v", m.chosen)
+	}
+	if m.focusIndex != m.submitIndex() {
+		t.Fatalf("expected the submit button to keep focus, got %d of %d", m.focusIndex, m.submitIndex())
+	}
+	if view := m.View(); !strings.Contains(view, "Site Reliability Engineer") || strings.Contains(view, "Loading") {
+		t.Fatalf("expected the roles to be listed:\n%s", view)
+	}
+}
@@ -0,0 +1,569 @@
+package ui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/nebulaworks/orion/apps/term-apply/pkg/applicant"
+)
+
+const staffPageSize = 10
+
+// StaffModel lets staff page through applicants and review them instead of
+// filling in the application form
+type StaffModel struct {
+	ctx      context.Context // scoped to the ssh session
+	appMgr   *applicant.ApplicantManager
+	userID   string
+	apps     []applicant.Application // the current page
+	cursors  []string                // the cursor each visited page was loaded from
+	next     string                  // cursor of the next page, empty on the last page
+	selected int
+	detail   bool           // showing the selected applicant instead of the list
+	open     string         // key of the applicant shown in the detail view
+	round    int            // the selected interview round in the detail view
+	role     int            // the selected applied role in the detail view
+	form     *interviewForm // the scorecard being edited, if any
+	loading  bool
+	message  string // outcome of the last action
+	err      error
+}
+
+// staffPageMsg carries a page of applications
+type staffPageMsg struct {
+	apps   []applicant.Application
+	cursor string
+	next   string
+	err    error
+}
+
+// staffSavedMsg reports the outcome of a staff edit
+type staffSavedMsg struct {
+	app     applicant.Application
+	message string
+	err     error
+}
+
+func InitialStaffModel(ctx context.Context, am *applicant.ApplicantManager, user string) StaffModel {
+	return StaffModel{
+		ctx:     ctx,
+		appMgr:  am,
+		userID:  user,
+		cursors: []string{""},
+		loading: true,
+	}
+}
+
+func (m StaffModel) Init() tea.Cmd {
+	return m.loadPage(m.currentCursor())
+}
+
+func (m StaffModel) currentCursor() string {
+	return m.cursors[len(m.cursors)-1]
+}
+
+// applicationKey identifies app within a page, since a page can be reloaded
+// in a different order while an edit is saved
+func applicationKey(app applicant.Application) string {
+	return fmt.Sprintf("%d:%s", app.AppliedDate, app.Email)
+}
+
+// findApplication returns the index of the application with key on the
+// current page
+func (m StaffModel) findApplication(key string) (int, bool) {
+	for i, app := range m.apps {
+		if applicationKey(app) == key {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// shownApplication returns the application open in the detail view, and
+// false if it is no longer on the current page
+func (m StaffModel) shownApplication() (applicant.Application, bool) {
+	if m.selected >= len(m.apps) || applicationKey(m.apps[m.selected]) != m.open {
+		return applicant.Application{}, false
+	}
+	return m.apps[m.selected], true
+}
+
+// A command that loads the page of applications after cursor
+func (m StaffModel) loadPage(cursor string) tea.Cmd {
+	ctx, appMgr := m.ctx, m.appMgr
+	return func() tea.Msg {
+		apps, next, err := appMgr.ListApplications(ctx, cursor, staffPageSize)
+		return staffPageMsg{apps: apps, cursor: cursor, next: next, err: err}
+	}
+}
+
+// A command that records a resume review for app
+func (m StaffModel) reviewResume(app applicant.Application, pass bool) tea.Cmd {
+	ctx, appMgr, userID := m.ctx, m.appMgr, m.userID
+	return func() tea.Msg {
+		saved, err := appMgr.ReviewResume(ctx, userID, app, pass)
+		if err == nil {
+			log.Printf("Staff %s reviewed resume for %s: pass=%t", userID, app.Github, pass)
+		}
+		return staffSavedMsg{app: saved, message: fmt.Sprintf("Resume marked %s", passFail(pass)), err: err}
+	}
+}
+
+// A command that adds or updates an interview round of app
+func (m StaffModel) saveInterview(app applicant.Application, round string, interview applicant.Interview) tea.Cmd {
+	ctx, appMgr, userID := m.ctx, m.appMgr, m.userID
+	return func() tea.Msg {
+		var saved applicant.Application
+		var err error
+		if round == "" {
+			saved, round, err = appMgr.AddInterview(ctx, userID, app, interview)
+		} else {
+			saved, err = appMgr.UpdateInterview(ctx, userID, app, round, interview)
+		}
+		if err == nil {
+			log.Printf("Staff %s saved interview round %s for %s", userID, round, app.Github)
+		}
+		return staffSavedMsg{app: saved, message: fmt.Sprintf("Interview round %s saved", round), err: err}
+	}
+}
+
+// A command that rejects app for the role with roleID
+func (m StaffModel) rejectRole(app applicant.Application, roleID string) tea.Cmd {
+	ctx, appMgr, userID := m.ctx, m.appMgr, m.userID
+	return func() tea.Msg {
+		saved, err := appMgr.RejectRole(ctx, userID, app, roleID)
+		if err == nil {
+			log.Printf("Staff %s rejected application for %s for role %s", userID, app.Github, roleID)
+		}
+		return staffSavedMsg{app: saved, message: fmt.Sprintf("Application rejected for %s", roleID), err: err}
+	}
+}
+
+// A command that sets the override of the role with roleID to override
+func (m StaffModel) overrideRole(app applicant.Application, roleID, override string) tea.Cmd {
+	ctx, appMgr, userID := m.ctx, m.appMgr, m.userID
+	return func() tea.Msg {
+		saved, err := appMgr.OverrideRole(ctx, userID, app, roleID, override)
+		if err == nil {
+			log.Printf("Staff %s set role override for %s on %s to %q", userID, app.Github, roleID, override)
+		}
+		message := fmt.Sprintf("Considering for %s instead of %s", override, roleID)
+		if override == "" {
+			message = fmt.Sprintf("Role override cleared for %s", roleID)
+		}
+		return staffSavedMsg{app: saved, message: message, err: err}
+	}
+}
+
+// nextOverride returns the role title after current in the catalog, cycling
+// back to no override
+func (m StaffModel) nextOverride(current string) string {
+	roles := m.appMgr.Roles()
+	for i, role := range roles {
+		if role.Title == current {
+			if i+1 < len(roles) {
+				return roles[i+1].Title
+			}
+			return ""
+		}
+	}
+	if len(roles) > 0 {
+		return roles[0].Title
+	}
+	return ""
+}
+
+// A command that moves app to state
+func (m StaffModel) transition(app applicant.Application, state applicant.State) tea.Cmd {
+	ctx, appMgr, userID := m.ctx, m.appMgr, m.userID
+	return func() tea.Msg {
+		saved, err := appMgr.TransitionApplication(ctx, userID, app, state)
+		if err == nil {
+			log.Printf("Staff %s moved application for %s to %s", userID, app.Github, state)
+		}
+		return staffSavedMsg{app: saved, message: fmt.Sprintf("Application moved to %s", state), err: err}
+	}
+}
+
+func (m StaffModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case staffPageMsg:
+		m.loading = false
+		m.err = msg.err
+		if msg.err != nil {
+			return m, nil
+		}
+		m.apps = msg.apps
+		m.next = msg.next
+		if m.detail {
+			// Keep showing the same applicant, wherever the reload put them
+			if i, ok := m.findApplication(m.open); ok {
+				m.selected = i
+			} else {
+				m.detail = false
+				m.message = "The application is no longer on this page"
+			}
+		}
+		if m.selected >= len(m.apps) {
+			m.selected = 0
+		}
+		return m, nil
+	case staffSavedMsg:
+		m.loading = false
+		var conflict *applicant.VersionConflictError
+		if errors.As(msg.err, &conflict) {
+			// Someone else changed the application, show them the latest
+			m.message = "The application was changed by someone else, reloaded the latest version"
+			m.form = nil
+			m.loading = true
+			return m, m.loadPage(m.currentCursor())
+		} else if msg.err != nil {
+			if m.form != nil {
+				m.form.err = msg.err
+				m.message = ""
+			} else {
+				m.message = fmt.Sprintf("Save failed: %v", msg.err)
+			}
+			return m, nil
+		}
+		m.form = nil
+		// The selection may have moved while saving, so update the row of
+		// the application that was saved
+		if i, ok := m.findApplication(applicationKey(msg.app)); ok {
+			m.apps[i] = msg.app
+		}
+		m.message = msg.message
+		return m, nil
+	case tea.KeyMsg:
+		if m.detail {
+			return m.updateDetail(msg)
+		}
+		return m.updateList(msg)
+	}
+	return m, nil
+}
+
+func (m StaffModel) updateList(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c", "esc", "q":
+		return m, tea.Quit
+	case "up", "k":
+		if m.selected > 0 {
+			m.selected--
+		}
+	case "down", "j":
+		if m.selected < len(m.apps)-1 {
+			m.selected++
+		}
+	case "enter":
+		if len(m.apps) > 0 {
+			m.detail = true
+			m.open = applicationKey(m.apps[m.selected])
+			m.round = 0
+			m.role = 0
+			m.message = ""
+		}
+	case "right", "n":
+		if m.next != "" && !m.loading {
+			m.cursors = append(m.cursors, m.next)
+			m.selected = 0
+			m.loading = true
+			return m, m.loadPage(m.next)
+		}
+	case "left", "p":
+		if len(m.cursors) > 1 && !m.loading {
+			m.cursors = m.cursors[:len(m.cursors)-1]
+			m.selected = 0
+			m.loading = true
+			return m, m.loadPage(m.currentCursor())
+		}
+	case "r":
+		if !m.loading {
+			m.loading = true
+			return m, m.loadPage(m.currentCursor())
+		}
+	}
+	return m, nil
+}
+
+func (m StaffModel) updateDetail(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.form != nil {
+		return m.updateForm(msg)
+	}
+
+	app, ok := m.shownApplication()
+	if !ok {
+		m.detail = false
+		return m, nil
+	}
+	rounds := app.InterviewRounds()
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "esc", "backspace", "q":
+		m.detail = false
+		m.message = ""
+	case "up", "k":
+		if m.round > 0 {
+			m.round--
+		}
+	case "down", "j":
+		if m.round < len(rounds)-1 {
+			m.round++
+		}
+	case "tab":
+		if roles := app.AppliedRoles(); len(roles) > 0 {
+			m.role = (m.role + 1) % len(roles)
+		}
+	case "d", "o":
+		roles := app.AppliedRoles()
+		if m.role < len(roles) && !m.loading {
+			m.loading = true
+			m.message = "Saving..."
+			role := roles[m.role]
+			if msg.String() == "d" {
+				return m, m.rejectRole(app, role.ID)
+			}
+			return m, m.overrideRole(app, role.ID, m.nextOverride(role.Override))
+		}
+	case "i":
+		form := newInterviewForm("", applicant.Interview{Interviewer: m.userID})
+		m.form = &form
+		m.message = ""
+		return m, textinput.Blink
+	case "e":
+		if m.round < len(rounds) {
+			round := rounds[m.round]
+			form := newInterviewForm(round, app.Interviews[round])
+			m.form = &form
+			m.message = ""
+			return m, textinput.Blink
+		}
+	case "1", "2", "3", "4":
+		next := app.NextStates()
+		choice, _ := strconv.Atoi(msg.String())
+		if choice <= len(next) && !m.loading {
+			m.loading = true
+			m.message = "Saving..."
+			return m, m.transition(app, next[choice-1])
+		}
+	case "y", "x":
+		if !m.loading {
+			m.loading = true
+			m.message = "Saving..."
+			return m, m.reviewResume(app, msg.String() == "y")
+		}
+	}
+	return m, nil
+}
+
+func (m StaffModel) updateForm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "esc":
+		m.form = nil
+		return m, nil
+	case "ctrl+s":
+		if m.loading {
+			return m, nil
+		}
+		interview, err := m.form.interview(m.userID)
+		m.form.err = err
+		if err != nil {
+			return m, nil
+		}
+		m.loading = true
+		m.message = "Saving..."
+		app, ok := m.shownApplication()
+		if !ok {
+			m.form = nil
+			m.detail = false
+			return m, nil
+		}
+		return m, m.saveInterview(app, m.form.round, interview)
+	}
+
+	form, cmd := m.form.update(msg)
+	m.form = &form
+	return m, cmd
+}
+
+func (m StaffModel) View() string {
+	var b strings.Builder
+	if m.form != nil {
+		b.WriteString(m.form.view())
+		if m.message != "" {
+			fmt.Fprintf(&b, "\n\n %s", m.message)
+		}
+		return b.String()
+	}
+	if app, ok := m.shownApplication(); m.detail && ok {
+		b.WriteString(applicationDetailView(app, m.round, m.role))
+		b.WriteString("\n")
+		if m.message != "" {
+			fmt.Fprintf(&b, " %s\n\n", m.message)
+		}
+		b.WriteString(helpStyle.Render("y pass resume • x fail resume • 1-4 move state • tab select role • d reject for role • o override role • i add interview • ↑/↓ select interview • e edit interview • esc back"))
+		return b.String()
+	}
+
+	fmt.Fprintf(&b, "\n Applicants (page %d)\n\n", len(m.cursors))
+	if m.err != nil {
+		b.WriteString(errorStyle.Render(fmt.Sprintf(" Could not load applicants: %v\n", m.err)))
+	} else if m.loading && len(m.apps) == 0 {
+		b.WriteString(" Loading...\n")
+	} else if len(m.apps) == 0 {
+		b.WriteString(" No applications yet\n")
+	}
+	for i, app := range m.apps {
+		line := fmt.Sprintf(
+			"%-20s %-24s %-26s %s",
+			app.Github,
+			app.Name,
+			app.RoleTitles(),
+			applicationStage(app),
+		)
+		if i == m.selected {
+			b.WriteString(focusedStyle.Render("> "+line) + "\n")
+		} else {
+			b.WriteString("  " + line + "\n")
+		}
+	}
+	b.WriteString("\n")
+	if m.message != "" {
+		fmt.Fprintf(&b, " %s\n\n", m.message)
+	}
+	b.WriteString(helpStyle.Render("↑/↓ select • enter open • n/p page • r reload • q exit"))
+	return b.String()
+}
+
+func applicationDetailView(app applicant.Application, selectedRound, selectedRole int) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "\n %s (%s)\n\n", app.Name, app.Github)
+	fmt.Fprintf(&b, " Email:         %s\n", app.Email)
+	fmt.Fprintf(&b, " Applied:       %s\n", formatUnix(app.AppliedDate))
+	fmt.Fprintf(&b, " State:         %s\n", app.CurrentState())
+	for i, role := range app.AppliedRoles() {
+		line := fmt.Sprintf("Role: %s", role.Title)
+		if role.Rejected {
+			line += fmt.Sprintf(", rejected on %s", formatUnix(role.RejectedDate))
+		}
+		if role.Override != "" {
+			line += fmt.Sprintf(", considered for %s", role.Override)
+		}
+		if i == selectedRole {
+			b.WriteString(focusedStyle.Render("> "+line) + "\n")
+		} else {
+			b.WriteString("  " + line + "\n")
+		}
+	}
+	if app.ResumeReviewDate != 0 {
+		fmt.Fprintf(&b, " Resume review: %s on %s\n", passFail(app.ResumeReview), formatUnix(app.ResumeReviewDate))
+	} else {
+		b.WriteString(" Resume review: pending\n")
+	}
+	for i, round := range app.InterviewRounds() {
+		interview := app.Interviews[round]
+		line := fmt.Sprintf(
+			"Interview %s: %s on %s, rated %d by %s",
+			round,
+			passFail(interview.Pass),
+			formatUnix(interview.Date),
+			interview.Rating,
+			interview.Interviewer,
+		)
+		if i == selectedRound {
+			b.WriteString(focusedStyle.Render("> "+line) + "\n")
+		} else {
+			b.WriteString("  " + line + "\n")
+		}
+		if len(interview.Competencies) > 0 {
+			fmt.Fprintf(&b, "   %s\n", formatCompetencies(interview.Competencies))
+		}
+		if len(interview.Notes) > 0 {
+			fmt.Fprintf(&b, "   %s\n", string(interview.Notes))
+		}
+	}
+	for _, question := range sortedAnswers(app.Answers) {
+		fmt.Fprintf(&b, " Answered:      %s: %s\n", question, app.Answers[question])
+	}
+	if app.IgnoreWorkflow {
+		b.WriteString(" Ignored by the automation workflow\n")
+	}
+	for _, flag := range sortedFlags(app.Flags) {
+		fmt.Fprintf(&b, " Flagged:       %s on %s\n", flag, formatUnix(app.Flags[flag]))
+	}
+	if app.OfferGiven {
+		fmt.Fprintf(&b, " Offer given:   %s\n", formatUnix(app.OfferDate))
+	}
+	if app.OfferAccepted {
+		fmt.Fprintf(&b, " Offer accepted: %s\n", formatUnix(app.OfferAcceptedDate))
+	}
+	if app.Rejected {
+		fmt.Fprintf(&b, " Rejected:      %s\n", formatUnix(app.RejectedDate))
+	}
+	if len(app.RejectedMsgOverride) > 0 {
+		fmt.Fprintf(&b, " Rejection message: %s\n", string(app.RejectedMsgOverride))
+	}
+	if next := app.NextStates(); len(next) > 0 {
+		moves := make([]string, len(next))
+		for i, state := range next {
+			moves[i] = fmt.Sprintf("%d %s", i+1, state)
+		}
+		fmt.Fprintf(&b, "\n Move to: %s\n", strings.Join(moves, " • "))
+	}
+	return b.String()
+}
+
+// applicationStage summarizes where app is in the pipeline for the list
+func applicationStage(app applicant.Application) string {
+	switch state := app.CurrentState(); state {
+	case applicant.StateApplied:
+		return "resume pending"
+	case applicant.StateResumeReviewed:
+		return "resume " + passFail(app.ResumeReview)
+	default:
+		return string(state)
+	}
+}
+
+func passFail(pass bool) string {
+	if pass {
+		return "passed"
+	}
+	return "failed"
+}
+
+func formatUnix(unix int64) string {
+	if unix == 0 {
+		return "-"
+	}
+	return time.Unix(unix, 0).UTC().Format("2006-01-02")
+}
+
+func sortedAnswers(answers map[string]string) []string {
+	questions := make([]string, 0, len(answers))
+	for question := range answers {
+		questions = append(questions, question)
+	}
+	sort.Strings(questions)
+	return questions
+}
+
+func sortedFlags(flags map[string]int64) []string {
+	names := make([]string, 0, len(flags))
+	for name := range flags {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,116 @@
+package ui
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/nebulaworks/orion/apps/term-apply/pkg/applicant"
+)
+
+func testApps() []applicant.Application {
+	return []applicant.Application{
+		{AppliedDate: 100, Github: "candy", Name: "Candy Date", Email: "candy@date.com", Version: 1},
+		{AppliedDate: 200, Github: "dandy", Name: "Dandy Date", Email: "dandy@date.com", Version: 1},
+	}
+}
+
+func updateStaff(t *testing.T, m StaffModel, msg tea.Msg) StaffModel {
+	t.Helper()
+	updated, _ := m.Update(msg)
+	return updated.(StaffModel)
+}
+
+func keyMsg(key string) tea.KeyMsg {
+	switch key {
+	case "enter":
+		return tea.KeyMsg{Type: tea.KeyEnter}
+	case "esc":
+		return tea.KeyMsg{Type: tea.KeyEsc}
+	case "down":
+		return tea.KeyMsg{Type: tea.KeyDown}
+	case "tab":
+		return tea.KeyMsg{Type: tea.KeyTab}
+	}
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)}
+}
+
+func TestStaffSaveUpdatesSavedRow(t *testing.T) {
+	m := StaffModel{cursors: []string{""}}
+	m = updateStaff(t, m, staffPageMsg{apps: testApps()})
+
+	// Staff open the first applicant, start a save, and go back to the list
+	// and select the second applicant before it finishes
+	m = updateStaff(t, m, keyMsg("enter"))
+	m.loading = true
+	m = updateStaff(t, m, keyMsg("esc"))
+	m = updateStaff(t, m, keyMsg("down"))
+	if m.selected != 1 {
+		t.Fatalf("expected the second applicant to be selected, got %d", m.selected)
+	}
+
+	saved := testApps()[0]
+	saved.Version = 2
+	saved.ResumeReview = true
+	m = updateStaff(t, m, staffSavedMsg{app: saved, message: "Resume marked passed"})
+	if m.apps[0].Version != 2 || m.apps[1].Github != "dandy" || m.apps[1].Version != 1 {
+		t.Fatalf("expected only the saved applicant's row to change: %+v", m.apps)
+	}
+}
+
+func TestStaffConflictReloadKeepsApplicant(t *testing.T) {
+	m := StaffModel{cursors: []string{""}}
+	m = updateStaff(t, m, staffPageMsg{apps: testApps()})
+	m = updateStaff(t, m, keyMsg("down"))
+	m = updateStaff(t, m, keyMsg("enter"))
+
+	m = updateStaff(t, m, staffSavedMsg{err: &applicant.VersionConflictError{}})
+	if !m.loading {
+		t.Fatalf("expected a conflict to reload the page")
+	}
+
+	// The reloaded page lists the applicants in a different order
+	apps := testApps()
+	apps[0], apps[1] = apps[1], apps[0]
+	m = updateStaff(t, m, staffPageMsg{apps: apps})
+	if !m.detail || m.apps[m.selected].Github != "dandy" {
+		t.Fatalf("expected the detail view to stay on the same applicant, showing %s", m.apps[m.selected].Github)
+	}
+}
+
+func TestStaffConflictReloadClosesMissingApplicant(t *testing.T) {
+	m := StaffModel{cursors: []string{""}}
+	m = updateStaff(t, m, staffPageMsg{apps: testApps()})
+	m = updateStaff(t, m, keyMsg("down"))
+	m = updateStaff(t, m, keyMsg("enter"))
+
+	m = updateStaff(t, m, staffSavedMsg{err: &applicant.VersionConflictError{}})
+	m = updateStaff(t, m, staffPageMsg{})
+	if m.detail {
+		t.Fatalf("expected the detail view to close once the applicant is gone")
+	}
+	// Neither rendering nor keys may index the empty page
+	m.View()
+	m = updateStaff(t, m, keyMsg("y"))
+	if m.loading {
+		t.Fatalf("expected no save without an applicant")
+	}
+}
+
+func TestStaffRoleRejectUpdatesSavedRow(t *testing.T) {
+	apps := testApps()
+	apps[0].Roles = []applicant.AppliedRole{{ID: "swe", Title: "Software Engineer"}, {ID: "sre", Title: "Site Reliability Engineer"}}
+	m := StaffModel{cursors: []string{""}}
+	m = updateStaff(t, m, staffPageMsg{apps: apps})
+	m = updateStaff(t, m, keyMsg("enter"))
+	m = updateStaff(t, m, keyMsg("tab"))
+	m.loading = true
+	m = updateStaff(t, m, keyMsg("esc"))
+	m = updateStaff(t, m, keyMsg("down"))
+
+	saved := apps[0]
+	saved.Roles = []applicant.AppliedRole{apps[0].Roles[0], {ID: "sre", Title: "Site Reliability Engineer", Rejected: true}}
+	m = updateStaff(t, m, staffSavedMsg{app: saved, message: "Application rejected for sre"})
+	if !m.apps[0].Roles[1].Rejected || m.apps[1].Github != "dandy" || len(m.apps[1].Roles) != 0 {
+		t.Fatalf("expected the role rejection to land on the saved applicant: %+v", m.apps)
+	}
+}
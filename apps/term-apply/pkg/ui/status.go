@@ -0,0 +1,67 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/nebulaworks/orion/apps/term-apply/pkg/applicant"
+)
+
+// statusMsg carries the latest application status for the user
+type statusMsg struct {
+	status *applicant.Status
+	err    error
+}
+
+// A command that loads the status of the user's latest application
+func (m *Model) loadStatus() tea.Cmd {
+	ctx, appMgr, userID := m.ctx, m.appMgr, m.userID
+	return func() tea.Msg {
+		status, err := appMgr.ApplicationStatus(ctx, userID)
+		return statusMsg{status: status, err: err}
+	}
+}
+
+func statusView(status *applicant.Status, err error) string {
+	if err != nil {
+		return errorStyle.Render("\n Application status is unavailable right now.\n")
+	}
+	if status == nil {
+		return ""
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "\n Application status for %s:\n", status.RoleApplied)
+	if len(status.Roles) > 1 {
+		for _, role := range status.Roles {
+			fmt.Fprintf(&b, "   %-26s %s\n", role.Title, role.Outcome)
+		}
+		b.WriteString("\n")
+	}
+	for _, step := range status.Steps {
+		line := fmt.Sprintf("   %s %s", stepMarker(step.Done), step.Title)
+		if step.Date.Unix() > 0 {
+			line += fmt.Sprintf(" (%s)", step.Date.Format("2006-01-02"))
+		}
+		if step.Done {
+			b.WriteString(line + "\n")
+		} else {
+			b.WriteString(blurredStyle.Render(line) + "\n")
+		}
+	}
+	if status.Next != "" {
+		b.WriteString(blurredStyle.Render(fmt.Sprintf("   %s %s", stepMarker(false), status.Next)) + "\n")
+	}
+	if status.Message != "" {
+		fmt.Fprintf(&b, "\n %s\n", status.Message)
+	}
+	return b.String()
+}
+
+func stepMarker(done bool) string {
+	if done {
+		return focusedStyle.Render("✓")
+	}
+	return "•"
+}
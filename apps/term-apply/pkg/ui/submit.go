@@ -0,0 +1,60 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/nebulaworks/orion/apps/term-apply/pkg/applicant"
+)
+
+// submitResultMsg reports the outcome of persisting an application
+type submitResultMsg struct {
+	receipt applicant.Receipt
+	err     error
+}
+
+// A command that submits the application and waits for it to be persisted
+func (m *Model) submitApplication() tea.Cmd {
+	ctx, appMgr, userID := m.ctx, m.appMgr, m.userID
+	name, email := m.inputs[0].Value(), m.inputs[1].Value()
+	var roleIDs []string
+	for _, role := range m.chosenRoles() {
+		roleIDs = append(roleIDs, role.ID)
+	}
+	answers, _ := m.answerMap()
+	return func() tea.Msg {
+		receipt, err := appMgr.AddApplicant(ctx, userID, name, email, roleIDs, answers)
+		if err != nil {
+			// Candidates only see a summary of why
+			log.Printf("Submission for %s failed: %v", userID, err)
+		}
+		return submitResultMsg{receipt: receipt, err: err}
+	}
+}
+
+// submitErrorView explains a failed submission to the candidate. Storage
+// errors are only logged by the server, so their details are not shown.
+func submitErrorView(err error) string {
+	var (
+		queued   *applicant.WriteQueuedError
+		closed   *applicant.RoleClosedError
+		invalid  *applicant.InvalidInputError
+		conflict *applicant.VersionConflictError
+	)
+	switch {
+	case errors.Is(err, errNoRole) || errors.Is(err, errUnanswered):
+		return errorStyle.Render(fmt.Sprintf("\n Your application was not saved: %v\n", err))
+	case errors.As(err, &queued):
+		return errorStyle.Render("\n We could not save your application yet.\n It has been queued and will be saved automatically, no need to submit again.\n")
+	case errors.As(err, &closed):
+		return errorStyle.Render(fmt.Sprintf("\n Your application was not saved: %v.\n Please choose another role.\n", closed))
+	case errors.As(err, &invalid):
+		return errorStyle.Render(fmt.Sprintf("\n Your application was not saved, please check your %s.\n", strings.Join(invalid.Fields, " and ")))
+	case errors.As(err, &conflict):
+		return errorStyle.Render("\n Your application changed while it was being saved, please submit it again.\n")
+	}
+	return errorStyle.Render("\n We could not save your application right now, please try again in a few minutes.\n")
+}
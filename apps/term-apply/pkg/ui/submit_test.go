@@ -0,0 +1,37 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/nebulaworks/orion/apps/term-apply/pkg/applicant"
+)
+
+func TestSubmitErrorView(t *testing.T) {
+	internal := errors.New("RequestError: send request failed, table term-apply-applications, request id 4F8G2K")
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"queued", &applicant.WriteQueuedError{Err: internal}, "saved automatically"},
+		{"storage", fmt.Errorf("failed to count applications: %w", internal), "try again in a few minutes"},
+		{"closed role", &applicant.RoleClosedError{Role: applicant.Role{Title: "Software Engineer"}}, "Software Engineer is no longer accepting applications"},
+		{"invalid input", &applicant.InvalidInputError{Fields: []string{"name", "e-mail"}}, "check your name and e-mail"},
+		{"conflict", &applicant.VersionConflictError{Email: "candy@date.com"}, "submit it again"},
+		{"no role", errNoRole, "please choose at least one role"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			view := plain(submitErrorView(tt.err))
+			if !strings.Contains(view, tt.want) {
+				t.Errorf("expected %q in %q", tt.want, view)
+			}
+			if strings.Contains(view, "4F8G2K") || strings.Contains(view, "candy@date.com") {
+				t.Errorf("internal details shown to the candidate: %q", view)
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package ui
 
 import (
 	"fmt"
+	"log"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/gliderlabs/ssh"
@@ -10,20 +11,31 @@ import (
 
 type TeaManager struct {
 	Appmgr *applicant.ApplicantManager
+	staff  map[string]bool // GitHub users allowed into the staff reviewer
 }
 
-func NewTeaManager(applicantManager *applicant.ApplicantManager) *TeaManager {
+func NewTeaManager(applicantManager *applicant.ApplicantManager, staffUsers []string) *TeaManager {
+	staff := make(map[string]bool, len(staffUsers))
+	for _, user := range staffUsers {
+		staff[user] = true
+	}
 	return &TeaManager{
 		Appmgr: applicantManager,
+		staff:  staff,
 	}
 }
 
 func (t *TeaManager) TeaHandler(s ssh.Session) (tea.Model, []tea.ProgramOption) {
-	_, _, active := s.Pty()
+	pty, _, active := s.Pty()
 	if !active {
 		fmt.Println("no active terminal, skipping")
 		return nil, nil
 	}
-	m := InitialModel(t.Appmgr, s.User())
+	if t.staff[s.User()] {
+		log.Printf("Starting staff reviewer for %s", s.User())
+		return InitialStaffModel(s.Context(), t.Appmgr, s.User()), []tea.ProgramOption{tea.WithAltScreen()}
+	}
+	m := InitialModel(s.Context(), t.Appmgr, s.User())
+	m.width, m.height = pty.Window.Width, pty.Window.Height
 	return m, []tea.ProgramOption{tea.WithAltScreen()}
 }
@@ -0,0 +1,43 @@
+package ui
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// withdrawResultMsg reports the outcome of withdrawing the application
+type withdrawResultMsg struct {
+	err error
+}
+
+// A command that withdraws the user's latest application
+func (m *Model) withdrawApplication() tea.Cmd {
+	ctx, appMgr, userID := m.ctx, m.appMgr, m.userID
+	return func() tea.Msg {
+		_, err := appMgr.WithdrawApplication(ctx, userID)
+		return withdrawResultMsg{err: err}
+	}
+}
+
+func (m Model) canWithdraw() bool {
+	return m.status != nil && m.status.CanWithdraw && !m.withdrawing
+}
+
+func (m Model) withdrawView() string {
+	switch {
+	case m.confirmWithdraw:
+		return errorStyle.Render(fmt.Sprintf(
+			"\n Withdraw your application for %s? This cannot be undone.\n Press y to withdraw, any other key to keep it.\n",
+			m.status.RoleApplied,
+		))
+	case m.withdrawing:
+		return "\n Withdrawing your application...\n"
+	case m.withdrawErr != nil:
+		return errorStyle.Render(fmt.Sprintf("\n Your application was not withdrawn: %v\n", m.withdrawErr))
+	case m.canWithdraw():
+		return helpStyle.Render("\n ctrl+x to withdraw your application\n")
+	default:
+		return ""
+	}
+}
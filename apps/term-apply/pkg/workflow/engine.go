@@ -0,0 +1,245 @@
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nebulaworks/orion/apps/term-apply/pkg/applicant"
+)
+
+const pageSize = 100
+
+// Manager is the part of the applicant manager the engine acts through, so
+// every change takes the applicant's lock and is recorded in the audit trail
+type Manager interface {
+	ListApplications(ctx context.Context, cursor string, limit int) ([]applicant.Application, string, error)
+	HasResume(ctx context.Context, github string) (bool, error)
+	TransitionApplication(ctx context.Context, actor string, app applicant.Application, state applicant.State) (applicant.Application, error)
+	FlagApplication(ctx context.Context, actor string, app applicant.Application, flag string) (applicant.Application, error)
+}
+
+type Config struct {
+	DryRun   bool          // report what rules would do without changing anything
+	Interval time.Duration // how often rules are run by Start
+	Timeout  time.Duration // how long a run started by Start may take, the interval by default
+}
+
+// Engine runs workflow rules over every application, skipping applications
+// with ignore_workflow set
+type Engine struct {
+	am     Manager
+	rules  []Rule
+	config Config
+	now    func() time.Time
+
+	mu      sync.Mutex
+	cancel  context.CancelFunc // cancels the runs started by Start
+	stopped bool
+	running sync.WaitGroup
+}
+
+// Match is an application a rule matched and what was done about it
+type Match struct {
+	Rule        string
+	Action      Action
+	Github      string
+	Email       string
+	AppliedDate int64
+	Err         error // why the action failed, nil if it succeeded or is a dry run
+}
+
+// Report summarizes one run of the rules
+type Report struct {
+	DryRun  bool
+	Checked int // applications evaluated
+	Ignored int // applications skipped for ignore_workflow
+	Matches []Match
+}
+
+func NewEngine(am Manager, rules []Rule, config Config) (*Engine, error) {
+	if err := checkRules(rules); err != nil {
+		return nil, err
+	}
+	if config.Interval <= 0 {
+		config.Interval = time.Hour
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = config.Interval
+	}
+	return &Engine{
+		am:     am,
+		rules:  rules,
+		config: config,
+		now:    time.Now,
+	}, nil
+}
+
+// Start runs the rules every interval until the engine is stopped
+func (e *Engine) Start() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.stopped || e.cancel != nil {
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	e.cancel = cancel
+
+	e.running.Add(1)
+	go func() {
+		defer e.running.Done()
+		ticker := time.NewTicker(e.config.Interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				e.runOnce(ctx)
+			}
+		}
+	}()
+}
+
+// runOnce runs the rules and logs the report, giving up after the timeout
+func (e *Engine) runOnce(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, e.config.Timeout)
+	defer cancel()
+
+	report, err := e.Run(ctx)
+	if err != nil {
+		log.Printf("Workflow run failed: %v", err)
+	}
+	log.Print(report)
+}
+
+// Stop cancels any run in progress and waits for it to return. The engine
+// cannot be started again.
+func (e *Engine) Stop() {
+	e.mu.Lock()
+	e.stopped = true
+	cancel := e.cancel
+	e.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+	}
+	e.running.Wait()
+}
+
+// Run evaluates every rule against every application once. Actions are only
+// applied when the engine is not a dry run. The report covers every
+// application evaluated before an error listing applications.
+func (e *Engine) Run(ctx context.Context) (Report, error) {
+	report := Report{DryRun: e.config.DryRun}
+	now := e.now()
+
+	cursor := ""
+	for {
+		apps, next, err := e.am.ListApplications(ctx, cursor, pageSize)
+		if err != nil {
+			return report, fmt.Errorf("failed to list applications: %w", err)
+		}
+		for _, app := range apps {
+			if app.IgnoreWorkflow {
+				report.Ignored++
+				continue
+			}
+			report.Checked++
+			report.Matches = append(report.Matches, e.evaluate(ctx, app, now)...)
+		}
+		if next == "" {
+			return report, nil
+		}
+		cursor = next
+	}
+}
+
+// evaluate runs the rules in order against app. Later rules see the changes
+// made by earlier ones.
+func (e *Engine) evaluate(ctx context.Context, app applicant.Application, now time.Time) []Match {
+	var matches []Match
+	resumeChecked, hasResume := false, false
+	var resumeErr error
+
+	for _, rule := range e.rules {
+		if !rule.matches(app, now) {
+			continue
+		}
+		if rule.Action == ActionFlag && app.Flags[rule.Name] != 0 {
+			continue
+		}
+		if rule.Action == ActionReject && !app.CanTransition(applicant.StateRejected) {
+			continue
+		}
+		if rule.NoResume {
+			if !resumeChecked {
+				hasResume, resumeErr = e.am.HasResume(ctx, app.Github)
+				resumeChecked = true
+				if resumeErr != nil {
+					// Never act on a resume that could not be checked for
+					log.Printf("Skipping resume rules for %s, could not check for a resume: %v", app.Github, resumeErr)
+				}
+			}
+			if resumeErr != nil || hasResume {
+				continue
+			}
+		}
+
+		match := Match{
+			Rule:        rule.Name,
+			Action:      rule.Action,
+			Github:      app.Github,
+			Email:       app.Email,
+			AppliedDate: app.AppliedDate,
+		}
+		if !e.config.DryRun {
+			app, match.Err = e.apply(ctx, rule, app)
+		}
+		matches = append(matches, match)
+	}
+	return matches
+}
+
+func (e *Engine) apply(ctx context.Context, rule Rule, app applicant.Application) (applicant.Application, error) {
+	actor := "workflow:" + rule.Name
+	switch rule.Action {
+	case ActionReject:
+		return e.am.TransitionApplication(ctx, actor, app, applicant.StateRejected)
+	case ActionFlag:
+		return e.am.FlagApplication(ctx, actor, app, rule.Name)
+	default:
+		return app, fmt.Errorf("unknown workflow action %q", rule.Action)
+	}
+}
+
+func (r Report) String() string {
+	var b strings.Builder
+	mode := "Workflow run"
+	if r.DryRun {
+		mode = "Workflow dry run"
+	}
+	fmt.Fprintf(&b, "%s checked %d applications (%d ignored), %d matched", mode, r.Checked, r.Ignored, len(r.Matches))
+	for _, match := range r.Matches {
+		result := "done"
+		switch {
+		case r.DryRun:
+			result = "would " + string(match.Action)
+		case match.Err != nil:
+			result = "failed: " + match.Err.Error()
+		}
+		fmt.Fprintf(&b, "\n  %s: %s %s (%s, applied %s): %s",
+			match.Rule,
+			match.Action,
+			match.Github,
+			match.Email,
+			time.Unix(match.AppliedDate, 0).UTC().Format("2006-01-02"),
+			result,
+		)
+	}
+	return b.String()
+}
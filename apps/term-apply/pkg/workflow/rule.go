@@ -0,0 +1,161 @@
+package workflow
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/nebulaworks/orion/apps/term-apply/pkg/applicant"
+)
+
+/*
+
+Rules are loaded from a JSON file holding a list of rules, for example
+
+[
+  {"name": "no-resume", "age_days": 30, "no_resume": true, "action": "reject"},
+  {"name": "idle-resume-review", "states": ["applied"], "idle_days": 7, "action": "flag"}
+]
+
+A rule matches an application when every condition it sets holds. Rules
+without states only match open applications.
+
+*/
+
+type Action string
+
+const (
+	// ActionReject moves the application to rejected
+	ActionReject Action = "reject"
+	// ActionFlag records the rule in the application's flags for staff
+	ActionFlag Action = "flag"
+)
+
+const day = 24 * time.Hour
+
+type Rule struct {
+	Name     string            `json:"name"`
+	States   []applicant.State `json:"states,omitempty"`    // states the rule applies to, every open state when empty
+	AgeDays  int               `json:"age_days,omitempty"`  // days since the candidate applied
+	IdleDays int               `json:"idle_days,omitempty"` // days since the application last changed
+	NoResume bool              `json:"no_resume,omitempty"` // the candidate has not uploaded a resume
+	Action   Action            `json:"action"`
+}
+
+var knownStates = []applicant.State{
+	applicant.StateApplied,
+	applicant.StateResumeReviewed,
+	applicant.StateInterviewing,
+	applicant.StateOffer,
+	applicant.StateAccepted,
+	applicant.StateDeclined,
+	applicant.StateRejected,
+	applicant.StateWithdrawn,
+}
+
+// LoadRules reads and checks the rules in the JSON file at path
+func LoadRules(path string) ([]Rule, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read workflow rules: %w", err)
+	}
+	var rules []Rule
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return nil, fmt.Errorf("failed to parse workflow rules %s: %w", path, err)
+	}
+	return rules, checkRules(rules)
+}
+
+func checkRules(rules []Rule) error {
+	names := map[string]bool{}
+	for i, rule := range rules {
+		var errs []string
+		if strings.TrimSpace(rule.Name) == "" {
+			errs = append(errs, "name")
+		} else if names[rule.Name] {
+			errs = append(errs, "duplicate name")
+		}
+		names[rule.Name] = true
+
+		if rule.Action != ActionReject && rule.Action != ActionFlag {
+			errs = append(errs, fmt.Sprintf("action %q", rule.Action))
+		}
+		if rule.AgeDays < 0 || rule.IdleDays < 0 {
+			errs = append(errs, "negative days")
+		}
+		// A rule with no conditions would act on every application
+		if rule.AgeDays == 0 && rule.IdleDays == 0 && !rule.NoResume {
+			errs = append(errs, "no conditions")
+		}
+		for _, state := range rule.States {
+			if !isKnownState(state) {
+				errs = append(errs, fmt.Sprintf("state %q", state))
+			}
+		}
+		if len(errs) > 0 {
+			return fmt.Errorf("invalid workflow rule %d (%s): %s", i+1, rule.Name, strings.Join(errs, ","))
+		}
+	}
+	return nil
+}
+
+func isKnownState(state applicant.State) bool {
+	for _, known := range knownStates {
+		if known == state {
+			return true
+		}
+	}
+	return false
+}
+
+// appliesTo reports whether app is in one of the rule's states
+func (r Rule) appliesTo(app applicant.Application) bool {
+	if len(r.States) == 0 {
+		return app.IsOpen()
+	}
+	current := app.CurrentState()
+	for _, state := range r.States {
+		if state == current {
+			return true
+		}
+	}
+	return false
+}
+
+// matches reports whether the rule's time based conditions hold for app. The
+// resume is checked separately since it needs the blob store.
+func (r Rule) matches(app applicant.Application, now time.Time) bool {
+	if !r.appliesTo(app) {
+		return false
+	}
+	if r.AgeDays > 0 && now.Sub(time.Unix(app.AppliedDate, 0)) < time.Duration(r.AgeDays)*day {
+		return false
+	}
+	if r.IdleDays > 0 && now.Sub(time.Unix(lastActivity(app), 0)) < time.Duration(r.IdleDays)*day {
+		return false
+	}
+	return true
+}
+
+// lastActivity returns when app last changed. Applications written before
+// states were recorded have no history, so review and interview dates count
+// too.
+func lastActivity(app applicant.Application) int64 {
+	last := app.AppliedDate
+	for _, change := range app.StateHistory {
+		if change.Date > last {
+			last = change.Date
+		}
+	}
+	if app.ResumeReviewDate > last {
+		last = app.ResumeReviewDate
+	}
+	for _, interview := range app.Interviews {
+		if interview.Date > last {
+			last = interview.Date
+		}
+	}
+	return last
+}
@@ -0,0 +1,304 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/nebulaworks/orion/apps/term-apply/pkg/applicant"
+	"github.com/nebulaworks/orion/apps/term-apply/pkg/blobstore"
+)
+
+const resumePrefix = "/term-apply/test/resumes"
+
+var (
+	now   = time.Unix(100*24*60*60, 0)
+	rules = []Rule{
+		{Name: "no-resume", AgeDays: 30, NoResume: true, Action: ActionReject},
+		{Name: "idle-resume-review", States: []applicant.State{applicant.StateApplied}, IdleDays: 7, Action: ActionFlag},
+	}
+)
+
+type testEnv struct {
+	store *applicant.MemoryStore
+	am    *applicant.ApplicantManager
+	blobs *blobstore.LocalStore
+}
+
+func newTestEnv(t *testing.T) testEnv {
+	t.Helper()
+	blobs, err := blobstore.NewLocalStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := applicant.NewMemoryStore()
+	am, err := applicant.NewApplicantManager(store, blobs, applicant.ManagerConfig{ResumePrefix: resumePrefix})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(am.Close)
+	return testEnv{store: store, am: am, blobs: blobs}
+}
+
+// apply stores an application made daysAgo days before now
+func (env testEnv) apply(t *testing.T, github string, daysAgo int, edit func(*applicant.Application)) {
+	t.Helper()
+	applied := now.Add(-time.Duration(daysAgo) * day).Unix()
+	app := applicant.Application{
+		AppliedDate:  applied,
+		Github:       github,
+		Email:        github + "@date.com",
+		State:        applicant.StateApplied,
+		StateHistory: []applicant.StateChange{{State: applicant.StateApplied, Date: applied}},
+	}
+	if edit != nil {
+		edit(&app)
+	}
+	if err := env.store.PutApplication(context.Background(), app); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func (env testEnv) uploadResume(t *testing.T, github string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "resume.pdf")
+	if err := os.WriteFile(path, []byte("%PDF"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := env.blobs.Put(context.Background(), path, resumePrefix+"/"+github+"-resume.pdf"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func (env testEnv) get(t *testing.T, github string) applicant.Application {
+	t.Helper()
+	app, err := env.store.GetApplication(context.Background(), github)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return app
+}
+
+func newTestEngine(t *testing.T, env testEnv, dryRun bool) *Engine {
+	t.Helper()
+	engine, err := NewEngine(env.am, rules, Config{DryRun: dryRun})
+	if err != nil {
+		t.Fatal(err)
+	}
+	engine.now = func() time.Time { return now }
+	return engine
+}
+
+// unreachableBlobStore fails every call, like S3 during an outage
+type unreachableBlobStore struct{}
+
+func (unreachableBlobStore) Put(ctx context.Context, filename, key string) error {
+	return errors.New("RequestError: send request failed")
+}
+
+func (unreachableBlobStore) Exists(ctx context.Context, key string) (bool, error) {
+	return false, errors.New("RequestError: send request failed")
+}
+
+func (unreachableBlobStore) LastModified(ctx context.Context, key string) (time.Time, error) {
+	return time.Time{}, errors.New("RequestError: send request failed")
+}
+
+func TestRunSkipsResumeRulesWhenResumesCannotBeChecked(t *testing.T) {
+	env := newTestEnv(t)
+	am, err := applicant.NewApplicantManager(env.store, unreachableBlobStore{}, applicant.ManagerConfig{ResumePrefix: resumePrefix})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(am.Close)
+	env.am = am
+	env.apply(t, "stale", 40, nil)
+	engine := newTestEngine(t, env, false)
+
+	if _, err := engine.Run(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	app := env.get(t, "stale")
+	if app.CurrentState() != applicant.StateApplied || app.Rejected {
+		t.Fatalf("an application should not be rejected when its resume cannot be checked: %+v", app)
+	}
+	if app.Flags["idle-resume-review"] == 0 {
+		t.Fatalf("rules that do not need the resume should still run: %+v", app)
+	}
+}
+
+// hangingManager lists applications like a store that never answers,
+// returning only once ctx is done. It reports the first run to start and
+// end.
+type hangingManager struct {
+	Manager
+	listing chan struct{}
+	ended   chan error
+}
+
+func (m hangingManager) ListApplications(ctx context.Context, cursor string, limit int) ([]applicant.Application, string, error) {
+	// Later runs are dropped rather than blocking the engine
+	select {
+	case m.listing <- struct{}{}:
+	default:
+	}
+	<-ctx.Done()
+	select {
+	case m.ended <- ctx.Err():
+	default:
+	}
+	return nil, "", ctx.Err()
+}
+
+func newHangingEngine(t *testing.T, config Config) (*Engine, hangingManager) {
+	t.Helper()
+	am := hangingManager{listing: make(chan struct{}, 1), ended: make(chan error, 1)}
+	engine, err := NewEngine(am, rules, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return engine, am
+}
+
+func TestStopCancelsRun(t *testing.T) {
+	engine, am := newHangingEngine(t, Config{Interval: time.Millisecond, Timeout: time.Hour})
+	engine.Start()
+	<-am.listing
+
+	stopped := make(chan struct{})
+	go func() {
+		engine.Stop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Stop did not return while a run was in progress")
+	}
+	if err := <-am.ended; !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected the run to be cancelled, got %v", err)
+	}
+}
+
+func TestRunTimesOut(t *testing.T) {
+	engine, am := newHangingEngine(t, Config{Interval: time.Millisecond, Timeout: 10 * time.Millisecond})
+	engine.Start()
+	defer engine.Stop()
+	<-am.listing
+
+	select {
+	case err := <-am.ended:
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("expected the run to time out, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("the run was not given a deadline")
+	}
+}
+
+func TestRunAppliesRules(t *testing.T) {
+	env := newTestEnv(t)
+	env.apply(t, "stale", 40, nil)
+	env.apply(t, "uploaded", 40, nil)
+	env.uploadResume(t, "uploaded")
+	env.apply(t, "fresh", 1, nil)
+	env.apply(t, "ignored", 40, func(app *applicant.Application) { app.IgnoreWorkflow = true })
+	env.apply(t, "reviewed", 40, func(app *applicant.Application) {
+		app.State = applicant.StateResumeReviewed
+		app.StateHistory = append(app.StateHistory, applicant.StateChange{State: applicant.StateResumeReviewed, Date: now.Add(-day).Unix()})
+	})
+	env.uploadResume(t, "reviewed")
+	engine := newTestEngine(t, env, false)
+
+	report, err := engine.Run(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Checked != 4 || report.Ignored != 1 {
+		t.Fatalf("unexpected report %s", report)
+	}
+
+	if app := env.get(t, "stale"); app.CurrentState() != applicant.StateRejected {
+		t.Fatalf("stale application without a resume should be rejected: %+v", app)
+	}
+	if app := env.get(t, "uploaded"); app.CurrentState() != applicant.StateApplied || app.Flags["idle-resume-review"] == 0 {
+		t.Fatalf("idle application with a resume should only be flagged: %+v", app)
+	}
+	if app := env.get(t, "fresh"); len(app.Flags) != 0 || app.Rejected {
+		t.Fatalf("fresh application should be left alone: %+v", app)
+	}
+	if app := env.get(t, "ignored"); len(app.Flags) != 0 || app.Rejected {
+		t.Fatalf("ignored application should be left alone: %+v", app)
+	}
+	if app := env.get(t, "reviewed"); len(app.Flags) != 0 || app.Rejected {
+		t.Fatalf("application reviewed with a resume should be left alone: %+v", app)
+	}
+
+	events, _ := env.am.History(context.Background(), "stale")
+	if len(events) != 1 || events[0].Actor != "workflow:no-resume" {
+		t.Fatalf("rejection should be recorded under the rule: %+v", events)
+	}
+
+	// Nothing is left to do on a second run
+	report, err = engine.Run(context.Background())
+	if err != nil || len(report.Matches) != 0 {
+		t.Fatalf("expected no matches on a second run, got %s, %v", report, err)
+	}
+}
+
+func TestDryRunChangesNothing(t *testing.T) {
+	env := newTestEnv(t)
+	env.apply(t, "stale", 40, nil)
+	engine := newTestEngine(t, env, true)
+
+	report, err := engine.Run(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.DryRun || len(report.Matches) != 2 {
+		t.Fatalf("expected the stale application to match both rules: %s", report)
+	}
+	if app := env.get(t, "stale"); app.Rejected || len(app.Flags) != 0 || app.Version != 1 {
+		t.Fatalf("dry run should not change applications: %+v", app)
+	}
+}
+
+func TestInvalidRules(t *testing.T) {
+	invalid := map[string]Rule{
+		"unnamed":       {AgeDays: 1, Action: ActionFlag},
+		"no conditions": {Name: "everyone", Action: ActionReject},
+		"bad action":    {Name: "delete", AgeDays: 1, Action: "delete"},
+		"bad state":     {Name: "pending", AgeDays: 1, States: []applicant.State{"pending"}, Action: ActionFlag},
+		"negative days": {Name: "negative", IdleDays: -1, Action: ActionFlag},
+	}
+	for name, rule := range invalid {
+		if err := checkRules([]Rule{rule}); err == nil {
+			t.Errorf("%s: expected rule to be rejected", name)
+		}
+	}
+	if err := checkRules([]Rule{rules[0], rules[0]}); err == nil {
+		t.Errorf("expected duplicate rule names to be rejected")
+	}
+}
+
+func TestLoadRules(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rules.json")
+	contents := `[
+  {"name": "no-resume", "age_days": 30, "no_resume": true, "action": "reject"},
+  {"name": "idle-resume-review", "states": ["applied"], "idle_days": 7, "action": "flag"}
+]`
+	if err := os.WriteFile(path, []byte(contents), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := LoadRules(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(loaded) != 2 || loaded[0].Name != "no-resume" || !loaded[0].NoResume || loaded[1].States[0] != applicant.StateApplied {
+		t.Fatalf("unexpected rules %+v", loaded)
+	}
+}